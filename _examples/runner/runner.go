@@ -71,6 +71,21 @@ func run(
 
 	log.Printf("authorized as %s", me.Username.Link())
 
+	webhookOpts := []tgb.WebhookOption{
+		tgb.WithDropPendingUpdates(true),
+		tgb.WithWebhookLogger(log.Default()),
+	}
+
+	pollerOpts := []tgb.PollerOption{
+		tgb.WithPollerLogger(log.Default()),
+	}
+
+	// receive only updates handled by router
+	if source, ok := handler.(tgb.AllowedUpdatesSource); ok {
+		webhookOpts = append(webhookOpts, tgb.WithWebhookAllowedUpdatesFrom(source))
+		pollerOpts = append(pollerOpts, tgb.WithPollerAllowedUpdatesFrom(source))
+	}
+
 	switch {
 	case do != nil:
 		return do(ctx, client)
@@ -79,8 +94,7 @@ func run(
 			handler,
 			client,
 			flagWebhookURL,
-			tgb.WithDropPendingUpdates(true),
-			tgb.WithWebhookLogger(log.Default()),
+			webhookOpts...,
 		).Run(
 			ctx,
 			flagWebhookListen,
@@ -89,27 +103,7 @@ func run(
 		err = tgb.NewPoller(
 			handler,
 			client,
-			tgb.WithPollerLogger(log.Default()),
-			tgb.WithPollerAllowedUpdates(
-				tg.UpdateTypeMessage,
-				tg.UpdateTypeEditedMessage,
-				tg.UpdateTypeChannelPost,
-				tg.UpdateTypeEditedChannelPost,
-				tg.UpdateTypeMessageReaction,
-				tg.UpdateTypeMessageReactionCount,
-				tg.UpdateTypeInlineQuery,
-				tg.UpdateTypeChosenInlineResult,
-				tg.UpdateTypeCallbackQuery,
-				tg.UpdateTypeShippingQuery,
-				tg.UpdateTypePreCheckoutQuery,
-				tg.UpdateTypePoll,
-				tg.UpdateTypePollAnswer,
-				tg.UpdateTypeMyChatMember,
-				tg.UpdateTypeChatMember,
-				tg.UpdateTypeChatJoinRequest,
-				tg.UpdateTypeChatBoost,
-				tg.UpdateTypeRemovedChatBoost,
-			),
+			pollerOpts...,
 		).Run(ctx)
 	}
 	return err
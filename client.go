@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -35,6 +40,12 @@ type Client struct {
 	me     *User
 	meLock sync.Mutex
 
+	// send requests without files as application/json
+	jsonEncoding bool
+
+	// client works with local Bot API server
+	localMode bool
+
 	interceptors []Interceptor
 	invoker      InterceptorInvoker
 }
@@ -64,6 +75,28 @@ func WithClientTestEnv() ClientOption {
 	}
 }
 
+// WithClientJSONEncoding makes Client send requests without files as application/json.
+// It's friendlier to debugging proxies and more compact for requests with big JSON arguments (reply_markup, entities, etc).
+// By default, such requests are sent as application/x-www-form-urlencoded.
+// Requests with files are always sent as multipart/form-data.
+func WithClientJSONEncoding() ClientOption {
+	return func(c *Client) {
+		c.jsonEncoding = true
+	}
+}
+
+// WithClientLocalMode enables features of local Bot API server, started with --local flag.
+// See https://github.com/tdlib/telegram-bot-api#usage
+//
+// Files created by [NewInputFileLocal] are passed by file:// URI instead of uploading,
+// and [Client.Download] reads files directly from disk if path is absolute.
+// It allows to work with files up to 2000 MB. Bot API server should have access to the same file system.
+func WithClientLocalMode() ClientOption {
+	return func(c *Client) {
+		c.localMode = true
+	}
+}
+
 // WithClientInterceptor adds interceptor to client.
 func WithClientInterceptors(ints ...Interceptor) ClientOption {
 	return func(c *Client) {
@@ -110,8 +143,59 @@ func (client *Client) Token() string {
 	return client.token
 }
 
+// redactedToken is the placeholder of bot token in redacted strings.
+const redactedToken = "<redacted>"
+
+// Redact replaces the bot token in s with placeholder.
+// Use it to sanitize strings before logging, e.g. URLs of Bot API requests.
+func (client *Client) Redact(s string) string {
+	if client.token == "" {
+		return s
+	}
+
+	return strings.ReplaceAll(s, client.token, redactedToken)
+}
+
+// RedactError returns the error with the bot token removed from its message.
+// Returned error wraps the original one, so [errors.Is] and [errors.As] still work.
+// Errors returned by Client are already redacted.
+func (client *Client) RedactError(err error) error {
+	if err == nil || client.token == "" {
+		return err
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = client.Redact(urlErr.URL)
+	}
+
+	if !strings.Contains(err.Error(), client.token) {
+		return err
+	}
+
+	return &redactedError{err: err, msg: client.Redact(err.Error())}
+}
+
+// redactedError is an error with the bot token removed from message.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (err *redactedError) Error() string {
+	return err.msg
+}
+
+func (err *redactedError) Unwrap() error {
+	return err.err
+}
+
 // Execute request at low-level
 func (client *Client) execute(ctx context.Context, r *Request) (*Response, error) {
+	if client.localMode {
+		r.useLocalFiles()
+	}
+
 	if len(r.files) > 0 {
 		return client.executeStreaming(
 			ctx,
@@ -120,6 +204,14 @@ func (client *Client) execute(ctx context.Context, r *Request) (*Response, error
 		)
 	}
 
+	if client.jsonEncoding {
+		return client.executeSimple(
+			ctx,
+			func(w io.Writer) httpEncoder { return newJSONEncoder(w) },
+			r,
+		)
+	}
+
 	return client.executeSimple(
 		ctx,
 		func(w io.Writer) httpEncoder { return newURLEncodedEncoder(w) },
@@ -197,8 +289,12 @@ func (client *Client) executeHTTPRequest(ctx context.Context, r *http.Request) (
 	}
 	defer res.Body.Close()
 
-	// TODO: handle status and content type
+	return parseHTTPResponse(res)
+}
 
+// parseHTTPResponse reads Bot API response from HTTP response.
+// Returns [HTTPError] if response is not a Bot API response.
+func parseHTTPResponse(res *http.Response) (*Response, error) {
 	// read content
 	content, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -211,7 +307,12 @@ func (client *Client) executeHTTPRequest(ctx context.Context, r *http.Request) (
 
 	// unmarshal content
 	if err := json.Unmarshal(content, &response); err != nil {
-		return nil, fmt.Errorf("unmarshal response: %w", err)
+		return nil, newHTTPError(res, content, fmt.Errorf("unmarshal response: %w", err))
+	}
+
+	// valid json, but not a Bot API error
+	if !response.Ok && response.ErrorCode == 0 {
+		return nil, newHTTPError(res, content, nil)
 	}
 
 	return response, nil
@@ -222,57 +323,70 @@ func (client *Client) executeStreaming(
 	newEncoder func(io.Writer) httpEncoder,
 	r *Request,
 ) (*Response, error) {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	if err := r.rewindFiles(); err != nil {
+		return nil, err
+	}
 
 	pr, pw := io.Pipe()
 
 	encoder := newEncoder(pw)
 
-	resChan := make(chan *Response)
-	errChan := make(chan error)
+	uploadDone := make(chan struct{})
 
 	// upload
 	go func() {
-		defer pw.Close()
-		defer encoder.Close()
+		defer close(uploadDone)
 
-		if err := r.Encode(encoder); err != nil {
-			errChan <- err
-		}
-	}()
-
-	// send
-	go func() {
-		req, err := client.buildHTTPRequest(r, pr, encoder.ContentType())
-		if err != nil {
-			errChan <- fmt.Errorf("build http request: %w", err)
-			return
+		err := r.Encode(encoder)
+		if err == nil {
+			err = encoder.Close()
 		}
 
-		res, err := client.executeHTTPRequest(ctx, req)
+		// nil error closes pipe as usual
 		if err != nil {
-			errChan <- fmt.Errorf("execute http request: %w", err)
-			return
+			err = &uploadError{err: err}
 		}
+		pw.CloseWithError(err)
+	}()
 
-		resChan <- res
+	// don't return until upload is finished,
+	// so files are not read after request is done (and can be safely rewound on retry).
+	defer func() {
+		pr.Close()
+		<-uploadDone
 	}()
 
-	select {
-	case err := <-errChan:
-		return nil, err
-	case res := <-resChan:
-		return res, nil
-	case <-ctx.Done():
-		return nil, ctx.Err()
+	req, err := client.buildHTTPRequest(r, pr, encoder.ContentType())
+	if err != nil {
+		return nil, fmt.Errorf("build http request: %w", err)
+	}
+
+	res, err := client.executeHTTPRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute http request: %w", err)
 	}
+
+	return res, nil
+}
+
+// uploadError is the error of encoding request body (e.g. reading of file to upload),
+// so it's distinguishable from network errors of transport.
+type uploadError struct {
+	err error
+}
+
+func (err *uploadError) Error() string {
+	return fmt.Sprintf("upload: %v", err.err)
+}
+
+func (err *uploadError) Unwrap() error {
+	return err.err
 }
 
 func (client *Client) invoke(ctx context.Context, req *Request, dst any) error {
 	res, err := client.execute(ctx, req)
 	if err != nil {
-		return fmt.Errorf("execute: %w", err)
+		return client.RedactError(fmt.Errorf("execute: %w", err))
 	}
 
 	if !res.Ok {
@@ -293,39 +407,85 @@ func (client *Client) invoke(ctx context.Context, req *Request, dst any) error {
 }
 
 func (client *Client) Do(ctx context.Context, req *Request, dst any) error {
+	if len(client.interceptors) > 0 {
+		ctx = context.WithValue(ctx, clientContextKey{}, client)
+	}
+
 	return client.invoker(ctx, req, dst)
 }
 
 // Download file by path from Client.GetFile method.
 // Don't forget to close ReadCloser.
+//
+// In local mode (see [WithClientLocalMode]) Bot API server returns absolute paths,
+// such files are opened directly from disk.
 func (client *Client) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	body, _, err := client.downloadRange(ctx, path, 0)
+	return body, err
+}
+
+// downloadRange opens file by path starting from offset.
+// Returns the offset body actually starts from, it's 0 if server doesn't support ranges.
+func (client *Client) downloadRange(ctx context.Context, path string, offset int64) (io.ReadCloser, int64, error) {
+	body, start, err := client.openDownload(ctx, path, offset)
+	if err != nil {
+		return nil, 0, client.RedactError(err)
+	}
+
+	return body, start, nil
+}
+
+func (client *Client) openDownload(ctx context.Context, path string, offset int64) (io.ReadCloser, int64, error) {
+	if client.localMode && filepath.IsAbs(path) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, 0, fmt.Errorf("open local file: %w", err)
+		}
+
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			_ = file.Close()
+			return nil, 0, fmt.Errorf("seek local file: %w", err)
+		}
+
+		return file, offset, nil
+	}
+
 	url := client.buildDownloadURL(client.token, path)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
 	if err != nil {
-		return nil, fmt.Errorf("new request: %w", err)
+		return nil, 0, fmt.Errorf("new request: %w", err)
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
 
 	res, err := client.doer.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
+		return nil, 0, fmt.Errorf("do request: %w", err)
 	}
 
-	if res.StatusCode != http.StatusOK {
-		defer res.Body.Close()
-
-		tgResponse := &Response{}
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return res.Body, 0, nil
+	case res.StatusCode == http.StatusPartialContent && offset > 0:
+		return res.Body, offset, nil
+	case res.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		_ = res.Body.Close()
+		return nil, 0, errDownloadRangeNotSatisfiable
+	}
 
-		if err := json.NewDecoder(res.Body).Decode(tgResponse); err != nil {
-			return nil, fmt.Errorf("unmarshal: %w", err)
-		}
+	defer res.Body.Close()
 
-		return nil, &Error{
-			Code:       tgResponse.ErrorCode,
-			Message:    tgResponse.Description,
-			Parameters: tgResponse.Parameters,
-		}
+	tgResponse, err := parseHTTPResponse(res)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return res.Body, nil
+	return nil, 0, &Error{
+		Code:       tgResponse.ErrorCode,
+		Message:    tgResponse.Description,
+		Parameters: tgResponse.Parameters,
+	}
 }
@@ -2,11 +2,19 @@ package tg
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"testing/iotest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -72,6 +80,108 @@ func TestClient_Download(t *testing.T) {
 	})
 }
 
+func TestClient_LocalMode(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "video.mp4")
+	require.NoError(t, os.WriteFile(path, []byte("video"), 0o600))
+
+	t.Run("Upload", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"), "should not upload files")
+			require.NoError(t, r.ParseForm())
+
+			assert.Equal(t, "file://"+filepath.ToSlash(path), r.PostForm.Get("document"))
+			assert.JSONEq(t, `[{"type":"video","media":"file://`+filepath.ToSlash(path)+`"}]`, r.PostForm.Get("media"))
+
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		}))
+
+		defer ts.Close()
+
+		client := New("1234:secret", WithClientDoer(ts.Client()), WithClientServerURL(ts.URL), WithClientLocalMode())
+
+		document, err := NewInputFileLocal(path)
+		require.NoError(t, err)
+		defer document.Close()
+
+		video, err := NewInputFileLocal(path)
+		require.NoError(t, err)
+		defer video.Close()
+
+		req := NewRequest("test").
+			InputFile("document", document).
+			InputMediaSlice("media", []InputMedia{
+				{Video: &InputMediaVideo{Media: NewFileArgUpload(video)}},
+			})
+
+		require.NoError(t, client.Do(context.Background(), req, nil))
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		client := New("1234:secret", WithClientServerURL("http://127.0.0.1:0"), WithClientLocalMode())
+
+		body, err := client.Download(context.Background(), path)
+		require.NoError(t, err)
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "video", string(data))
+	})
+}
+
+type doerFunc func(r *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClient_Redact(t *testing.T) {
+	client := New("1234:secret", WithClientDoer(doerFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, &url.Error{Op: r.Method, URL: r.URL.String(), Err: errors.New("connection refused")}
+	})))
+
+	assert.Equal(t, "https://api.telegram.org/bot<redacted>/getMe", client.Redact("https://api.telegram.org/bot1234:secret/getMe"))
+	assert.Equal(t, "test", (&Client{}).Redact("test"))
+
+	t.Run("Do", func(t *testing.T) {
+		err := client.Do(context.Background(), NewRequest("getMe"), nil)
+		require.Error(t, err)
+
+		assert.NotContains(t, err.Error(), "secret")
+		assert.Contains(t, err.Error(), "/bot<redacted>/getMe")
+
+		var urlErr *url.Error
+		require.ErrorAs(t, err, &urlErr, "should keep original error")
+		assert.NotContains(t, urlErr.Error(), "secret")
+	})
+
+	t.Run("Streaming", func(t *testing.T) {
+		req := NewRequest("sendDocument").InputFile("document", NewInputFileBytes("test.txt", []byte("test")))
+
+		err := client.Do(context.Background(), req, nil)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "secret")
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		_, err := client.Download(context.Background(), "photos/file_1.jpg")
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "secret")
+	})
+
+	t.Run("Wrapped", func(t *testing.T) {
+		err := client.RedactError(fmt.Errorf("wrapped: %w", io.EOF))
+		assert.Equal(t, "wrapped: EOF", err.Error())
+
+		err = client.RedactError(fmt.Errorf("token 1234:secret: %w", io.EOF))
+		assert.Equal(t, "token <redacted>: EOF", err.Error())
+		assert.ErrorIs(t, err, io.EOF)
+	})
+}
+
 func TestClient_Execute(t *testing.T) {
 	t.Run("Simple", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -96,6 +206,37 @@ func TestClient_Execute(t *testing.T) {
 		)
 	})
 
+	t.Run("JSON", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/bot1234:secret/sendMessage", r.URL.Path)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"chat_id":"1","text":"test","reply_markup":{"remove_keyboard":true}}`, string(body))
+
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		}))
+
+		defer ts.Close()
+
+		client := New("1234:secret",
+			WithClientDoer(ts.Client()),
+			WithClientServerURL(ts.URL),
+			WithClientJSONEncoding(),
+		)
+
+		res, err := client.execute(context.Background(),
+			NewRequest("sendMessage").
+				String("chat_id", "1").
+				String("text", "test").
+				JSON("reply_markup", NewReplyKeyboardRemove()),
+		)
+
+		require.NoError(t, err)
+		assert.True(t, res.Ok)
+	})
+
 	t.Run("Streaming", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			assert.Equal(t, "POST", r.Method)
@@ -155,6 +296,175 @@ func TestClient_Execute(t *testing.T) {
 	})
 }
 
+func TestClient_ExecuteHTTPError(t *testing.T) {
+	t.Run("HTML", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html><body>" + strings.Repeat("bad gateway ", 100) + "</body></html>"))
+		}))
+
+		defer ts.Close()
+
+		client := New("1234:secret", WithClientDoer(ts.Client()), WithClientServerURL(ts.URL))
+
+		err := client.Do(context.Background(), NewRequest("getMe"), nil)
+		require.Error(t, err)
+
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+
+		var tgErr *Error
+		assert.False(t, errors.As(err, &tgErr), "should not be Bot API error")
+
+		assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+		assert.Equal(t, "text/html", httpErr.ContentType)
+		assert.Len(t, httpErr.Body, 512)
+		assert.True(t, httpErr.IsGateway())
+	})
+
+	t.Run("JSONWithoutErrorCode", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message":"service unavailable"}`))
+		}))
+
+		defer ts.Close()
+
+		client := New("1234:secret", WithClientDoer(ts.Client()), WithClientServerURL(ts.URL))
+
+		err := client.Do(context.Background(), NewRequest("getMe"), nil)
+
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+		assert.NoError(t, httpErr.Unwrap())
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+		}))
+
+		defer ts.Close()
+
+		client := New("1234:secret", WithClientDoer(ts.Client()), WithClientServerURL(ts.URL))
+
+		body, err := client.Download(context.Background(), "photos/file_1.jpg")
+		assert.Nil(t, body)
+
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusGatewayTimeout, httpErr.StatusCode)
+	})
+}
+
+func TestClient_ExecuteStreamingRetry(t *testing.T) {
+	newServer := func(t *testing.T, bodies *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			file, _, err := r.FormFile("document")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: no file"}`))
+				return
+			}
+			defer file.Close()
+
+			content, err := io.ReadAll(file)
+			require.NoError(t, err)
+
+			*bodies = append(*bodies, string(content))
+
+			if len(*bodies) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"ok":false,"error_code":500,"description":"Internal Server Error"}`))
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		}))
+	}
+
+	newClient := func(ts *httptest.Server) *Client {
+		return New("1234:secret",
+			WithClientDoer(ts.Client()),
+			WithClientServerURL(ts.URL),
+			WithClientInterceptors(NewInterceptorRetryInternalServerError(
+				WithInterceptorRetryInternalServerErrorDelay(time.Millisecond),
+			)),
+		)
+	}
+
+	t.Run("Bytes", func(t *testing.T) {
+		var bodies []string
+
+		ts := newServer(t, &bodies)
+		defer ts.Close()
+
+		req := NewRequest("sendDocument").
+			InputFile("document", NewInputFileBytes("test.txt", []byte("package tg")))
+
+		err := newClient(ts).Do(context.Background(), req, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"package tg", "package tg"}, bodies)
+	})
+
+	t.Run("FS", func(t *testing.T) {
+		var bodies []string
+
+		ts := newServer(t, &bodies)
+		defer ts.Close()
+
+		file, err := NewInputFileFS(fstest.MapFS{
+			"test.txt": {Data: []byte("package tg")},
+		}, "test.txt")
+		require.NoError(t, err)
+
+		// hide io.Seeker, so the file should be reopened
+		file.Body = io.MultiReader(file.Body)
+
+		req := NewRequest("sendDocument").InputFile("document", file)
+
+		err = newClient(ts).Do(context.Background(), req, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"package tg", "package tg"}, bodies)
+	})
+
+	t.Run("EncodeError", func(t *testing.T) {
+		var bodies []string
+
+		ts := newServer(t, &bodies)
+		defer ts.Close()
+
+		errRead := errors.New("read error")
+
+		req := NewRequest("sendDocument").
+			InputFile("document", NewInputFile("test.txt", iotest.ErrReader(errRead)))
+
+		err := newClient(ts).Do(context.Background(), req, nil)
+
+		require.ErrorIs(t, err, errRead)
+	})
+
+	t.Run("NotRewindable", func(t *testing.T) {
+		var bodies []string
+
+		ts := newServer(t, &bodies)
+		defer ts.Close()
+
+		req := NewRequest("sendDocument").
+			InputFile("document", NewInputFile("test.txt", io.MultiReader(strings.NewReader("package tg"))))
+
+		err := newClient(ts).Do(context.Background(), req, nil)
+
+		require.ErrorIs(t, err, ErrInputFileNotRewindable)
+		assert.Equal(t, []string{"package tg"}, bodies)
+	})
+}
+
 func TestClientInterceptors(t *testing.T) {
 	t.Run("Simple", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,203 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrFileTooLarge is returned when the file exceeds max size of download.
+var ErrFileTooLarge = errors.New("file is too large")
+
+// ErrDownloadSizeMismatch is returned when the size of downloaded file doesn't match [File.FileSize].
+var ErrDownloadSizeMismatch = errors.New("downloaded size mismatch")
+
+// errDownloadRangeNotSatisfiable is returned when server responds 416 to range request.
+var errDownloadRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// downloadPartSuffix is appended to the path of not completed download.
+const downloadPartSuffix = ".part"
+
+type downloadOpts struct {
+	maxSize int64
+}
+
+// DownloadOption is an option for Client.DownloadFile and Client.DownloadToPath.
+type DownloadOption func(*downloadOpts)
+
+// WithDownloadMaxSize sets max size of downloaded file in bytes.
+// If the file is bigger, [ErrFileTooLarge] is returned.
+// Size is checked before download using [File.FileSize] and while downloading.
+func WithDownloadMaxSize(size int64) DownloadOption {
+	return func(o *downloadOpts) {
+		o.maxSize = size
+	}
+}
+
+// DownloadFile gets the file info by id and writes its content to w.
+// Returns [ErrDownloadSizeMismatch] if written length doesn't match the size reported by Bot API.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//
+//	file, err := client.DownloadFile(ctx, fileID, &buf,
+//	  tg.WithDownloadMaxSize(10 << 20),
+//	)
+func (client *Client) DownloadFile(ctx context.Context, fileID FileID, w io.Writer, opts ...DownloadOption) (*File, error) {
+	options := newDownloadOpts(opts)
+
+	file, err := client.getDownloadFile(ctx, fileID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := client.Download(ctx, file.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	defer body.Close()
+
+	if _, err := copyDownload(w, body, file, 0, options.maxSize); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// DownloadToPath gets the file info by id and saves its content to path.
+//
+// Content is written to temporary file with .part suffix, which is renamed to path after successful download.
+// If download is interrupted, the next call with the same path resumes it using HTTP Range request
+// (local Bot API server supports it). If server doesn't support ranges, download starts from the beginning.
+func (client *Client) DownloadToPath(ctx context.Context, fileID FileID, path string, opts ...DownloadOption) (*File, error) {
+	options := newDownloadOpts(opts)
+
+	file, err := client.getDownloadFile(ctx, fileID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	partPath := path + downloadPartSuffix
+
+	part, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open part file: %w", err)
+	}
+	defer part.Close()
+
+	if err := client.resumeDownload(ctx, file, part, options.maxSize); err != nil {
+		return nil, err
+	}
+
+	if err := part.Close(); err != nil {
+		return nil, fmt.Errorf("close part file: %w", err)
+	}
+
+	if err := os.Rename(partPath, path); err != nil {
+		return nil, fmt.Errorf("rename part file: %w", err)
+	}
+
+	return file, nil
+}
+
+// resumeDownload downloads file to part, continuing from the current size of part.
+func (client *Client) resumeDownload(ctx context.Context, file *File, part *os.File, maxSize int64) error {
+	stat, err := part.Stat()
+	if err != nil {
+		return fmt.Errorf("stat part file: %w", err)
+	}
+
+	offset := stat.Size()
+
+	// part is bigger than the file, it's from another download
+	if file.FileSize > 0 && offset > file.FileSize {
+		offset = 0
+	}
+
+	// already downloaded
+	if file.FileSize > 0 && offset == file.FileSize {
+		return nil
+	}
+
+	body, start, err := client.downloadRange(ctx, file.FilePath, offset)
+	// part could be complete already if file size is unknown, or it's from another download,
+	// so start from the beginning
+	if errors.Is(err, errDownloadRangeNotSatisfiable) {
+		body, start, err = client.downloadRange(ctx, file.FilePath, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+	defer body.Close()
+
+	if err := part.Truncate(start); err != nil {
+		return fmt.Errorf("truncate part file: %w", err)
+	}
+
+	if _, err := part.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("seek part file: %w", err)
+	}
+
+	if _, err := copyDownload(part, body, file, start, maxSize); err != nil {
+		if errors.Is(err, ErrFileTooLarge) || errors.Is(err, ErrDownloadSizeMismatch) {
+			_ = os.Remove(part.Name())
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func newDownloadOpts(opts []DownloadOption) *downloadOpts {
+	options := &downloadOpts{}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	return options
+}
+
+// getDownloadFile gets the file info and checks its size.
+func (client *Client) getDownloadFile(ctx context.Context, fileID FileID, options *downloadOpts) (*File, error) {
+	file, err := client.GetFile(fileID).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get file: %w", err)
+	}
+
+	if options.maxSize > 0 && file.FileSize > options.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, max %d bytes", ErrFileTooLarge, file.FileSize, options.maxSize)
+	}
+
+	return &file, nil
+}
+
+// copyDownload copies body of file to w.
+// Written is number of bytes already written to w by previous attempts.
+// Returns total number of written bytes.
+func copyDownload(w io.Writer, body io.Reader, file *File, written, maxSize int64) (int64, error) {
+	if maxSize > 0 {
+		body = io.LimitReader(body, maxSize-written+1)
+	}
+
+	n, err := io.Copy(w, body)
+	written += n
+
+	if err != nil {
+		return written, fmt.Errorf("copy: %w", err)
+	}
+
+	if maxSize > 0 && written > maxSize {
+		return written, fmt.Errorf("%w: more than %d bytes", ErrFileTooLarge, maxSize)
+	}
+
+	if file.FileSize > 0 && written != file.FileSize {
+		return written, fmt.Errorf("%w: expected %d bytes, got %d", ErrDownloadSizeMismatch, file.FileSize, written)
+	}
+
+	return written, nil
+}
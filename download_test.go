@@ -0,0 +1,190 @@
+package tg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDownloadTestServer(t *testing.T, content string, size int, ranges bool) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var rangeHeaders []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot1234:secret/getFile":
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprintf(w, `{"ok":true,"result":{"file_id":"file","file_unique_id":"unique","file_size":%d,"file_path":"documents/file.txt"}}`, size)
+		case "/file/bot1234:secret/documents/file.txt":
+			rangeHeaders = append(rangeHeaders, r.Header.Get("Range"))
+
+			if !ranges {
+				r.Header.Del("Range")
+			}
+
+			http.ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader(content))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+
+	t.Cleanup(ts.Close)
+
+	return ts, &rangeHeaders
+}
+
+func TestClient_DownloadFile(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		ts, _ := newDownloadTestServer(t, "content", 7, true)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		var buf bytes.Buffer
+
+		file, err := client.DownloadFile(context.Background(), "file", &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "documents/file.txt", file.FilePath)
+		assert.Equal(t, "content", buf.String())
+	})
+
+	t.Run("MaxSize", func(t *testing.T) {
+		ts, rangeHeaders := newDownloadTestServer(t, "content", 7, true)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		_, err := client.DownloadFile(context.Background(), "file", &bytes.Buffer{}, WithDownloadMaxSize(5))
+		require.ErrorIs(t, err, ErrFileTooLarge)
+		assert.Empty(t, *rangeHeaders, "should not download file")
+	})
+
+	t.Run("MaxSizeUnknownFileSize", func(t *testing.T) {
+		ts, _ := newDownloadTestServer(t, "content", 0, true)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		_, err := client.DownloadFile(context.Background(), "file", &bytes.Buffer{}, WithDownloadMaxSize(5))
+		require.ErrorIs(t, err, ErrFileTooLarge)
+	})
+
+	t.Run("ResumeCompleteUnknownSize", func(t *testing.T) {
+		ts, rangeHeaders := newDownloadTestServer(t, "content", 0, true)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		path := filepath.Join(t.TempDir(), "file.txt")
+		require.NoError(t, os.WriteFile(path+downloadPartSuffix, []byte("content"), 0o600))
+
+		_, err := client.DownloadToPath(context.Background(), "file", path)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data), "should restart download on 416")
+		assert.Equal(t, []string{"bytes=7-", ""}, *rangeHeaders)
+	})
+
+	t.Run("SizeMismatch", func(t *testing.T) {
+		ts, _ := newDownloadTestServer(t, "content", 10, true)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		_, err := client.DownloadFile(context.Background(), "file", &bytes.Buffer{})
+		require.ErrorIs(t, err, ErrDownloadSizeMismatch)
+	})
+}
+
+func TestClient_DownloadToPath(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		ts, rangeHeaders := newDownloadTestServer(t, "content", 7, true)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		path := filepath.Join(t.TempDir(), "file.txt")
+
+		_, err := client.DownloadToPath(context.Background(), "file", path)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data))
+		assert.Equal(t, []string{""}, *rangeHeaders)
+
+		assert.NoFileExists(t, path+downloadPartSuffix)
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		ts, rangeHeaders := newDownloadTestServer(t, "content", 7, true)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		path := filepath.Join(t.TempDir(), "file.txt")
+		require.NoError(t, os.WriteFile(path+downloadPartSuffix, []byte("cont"), 0o600))
+
+		_, err := client.DownloadToPath(context.Background(), "file", path)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data))
+		assert.Equal(t, []string{"bytes=4-"}, *rangeHeaders)
+	})
+
+	t.Run("ResumeNotSupported", func(t *testing.T) {
+		ts, rangeHeaders := newDownloadTestServer(t, "content", 7, false)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		path := filepath.Join(t.TempDir(), "file.txt")
+		require.NoError(t, os.WriteFile(path+downloadPartSuffix, []byte("cont"), 0o600))
+
+		_, err := client.DownloadToPath(context.Background(), "file", path)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data), "should restart download")
+		assert.Equal(t, []string{"bytes=4-"}, *rangeHeaders)
+	})
+
+	t.Run("ResumeCompleteUnknownSize", func(t *testing.T) {
+		ts, rangeHeaders := newDownloadTestServer(t, "content", 0, true)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		path := filepath.Join(t.TempDir(), "file.txt")
+		require.NoError(t, os.WriteFile(path+downloadPartSuffix, []byte("content"), 0o600))
+
+		_, err := client.DownloadToPath(context.Background(), "file", path)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data), "should restart download on 416")
+		assert.Equal(t, []string{"bytes=7-", ""}, *rangeHeaders)
+	})
+
+	t.Run("SizeMismatch", func(t *testing.T) {
+		ts, _ := newDownloadTestServer(t, "content", 10, true)
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		path := filepath.Join(t.TempDir(), "file.txt")
+
+		_, err := client.DownloadToPath(context.Background(), "file", path)
+		require.ErrorIs(t, err, ErrDownloadSizeMismatch)
+
+		assert.NoFileExists(t, path)
+		assert.NoFileExists(t, path+downloadPartSuffix)
+	})
+}
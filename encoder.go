@@ -1,6 +1,8 @@
 package tg
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -68,6 +70,82 @@ func (encoder *urlEncodedEncoder) Close() error {
 	return nil
 }
 
+// jsonValueEncoder is implemented by encoders which can write JSON arguments as is,
+// without converting them to string.
+type jsonValueEncoder interface {
+	WriteJSON(k string, v json.RawMessage) error
+}
+
+// jsonEncoder encodes the request as JSON object.
+type jsonEncoder struct {
+	dst    io.Writer
+	fields int
+}
+
+var (
+	_ httpEncoder      = (*jsonEncoder)(nil)
+	_ jsonValueEncoder = (*jsonEncoder)(nil)
+)
+
+func newJSONEncoder(dst io.Writer) *jsonEncoder {
+	return &jsonEncoder{dst: dst}
+}
+
+func (encoder *jsonEncoder) WriteString(k, v string) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return encoder.WriteJSON(k, value)
+}
+
+func (encoder *jsonEncoder) WriteJSON(k string, v json.RawMessage) error {
+	key, err := json.Marshal(k)
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.Buffer{}
+
+	if encoder.fields > 0 {
+		buf.WriteByte(',')
+	} else {
+		buf.WriteByte('{')
+	}
+
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(v)
+
+	if _, err := encoder.dst.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	encoder.fields++
+
+	return nil
+}
+
+func (encoder *jsonEncoder) WriteFile(k string, file InputFile) error {
+	return errors.New("jsonEncoder doesn't support files")
+}
+
+func (encoder *jsonEncoder) ContentType() string {
+	return "application/json"
+}
+
+func (encoder *jsonEncoder) Close() error {
+	closing := "}"
+	if encoder.fields == 0 {
+		closing = "{}"
+	}
+
+	_, err := io.WriteString(encoder.dst, closing)
+
+	return err
+}
+
 // multipartEncoder encodes the request using multipart encoding.
 type multipartEncoder struct {
 	w *multipart.Writer
@@ -92,6 +170,14 @@ func (enc *multipartEncoder) WriteFile(k string, file InputFile) error {
 		return fmt.Errorf("create form file '%s': %w", k, err)
 	}
 
+	if file.progress != nil {
+		writer = &progressWriter{
+			w:        writer,
+			total:    file.size(),
+			progress: file.progress,
+		}
+	}
+
 	if _, err := io.Copy(writer, file.Body); err != nil {
 		return fmt.Errorf("copy to form file '%s': %w", k, err)
 	}
@@ -99,6 +185,25 @@ func (enc *multipartEncoder) WriteFile(k string, file InputFile) error {
 	return nil
 }
 
+// progressWriter reports number of written bytes to callback.
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress InputFileProgress
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+
+	if n > 0 {
+		pw.written += int64(n)
+		pw.progress(pw.written, pw.total)
+	}
+
+	return n, err
+}
+
 // ContentType returns HTTP request content type.
 func (enc *multipartEncoder) ContentType() string {
 	return enc.w.FormDataContentType()
@@ -2,6 +2,7 @@ package tg
 
 import (
 	"bytes"
+	"io"
 	"strings"
 	"testing"
 
@@ -44,6 +45,42 @@ func TestURLEncodedEncoder_Close(t *testing.T) {
 	assert.NoError(t, encoder.Close())
 }
 
+func TestJSONEncoder(t *testing.T) {
+	t.Run("Fields", func(t *testing.T) {
+		buf := bytes.Buffer{}
+
+		encoder := newJSONEncoder(&buf)
+
+		assert.NoError(t, encoder.WriteString("chat_id", "1"))
+		assert.NoError(t, encoder.WriteJSON("reply_markup", []byte(`{"remove_keyboard":true}`)))
+		assert.NoError(t, encoder.Close())
+
+		assert.JSONEq(t, `{"chat_id":"1","reply_markup":{"remove_keyboard":true}}`, buf.String())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		buf := bytes.Buffer{}
+
+		encoder := newJSONEncoder(&buf)
+
+		assert.NoError(t, encoder.Close())
+
+		assert.Equal(t, `{}`, buf.String())
+	})
+
+	t.Run("WriteFile", func(t *testing.T) {
+		encoder := newJSONEncoder(nil)
+
+		assert.Error(t, encoder.WriteFile("a", InputFile{}))
+	})
+
+	t.Run("ContentType", func(t *testing.T) {
+		encoder := newJSONEncoder(nil)
+
+		assert.Equal(t, "application/json", encoder.ContentType())
+	})
+}
+
 func TestMultipartEncoder(t *testing.T) {
 	buf := bytes.Buffer{}
 
@@ -85,3 +122,46 @@ func TestMultipartEncoder_Close(t *testing.T) {
 
 	assert.NoError(t, encoder.Close())
 }
+
+func TestMultipartEncoder_WriteFileProgress(t *testing.T) {
+	t.Run("KnownSize", func(t *testing.T) {
+		buf := bytes.Buffer{}
+
+		encoder := newMultipartEncoder(&buf)
+
+		var written, total []int64
+
+		file := NewInputFileBytes("test.txt", bytes.Repeat([]byte("a"), 100_000)).
+			WithProgress(func(w, t int64) {
+				written = append(written, w)
+				total = append(total, t)
+			})
+
+		assert.NoError(t, encoder.WriteFile("document", file))
+		assert.NoError(t, encoder.Close())
+
+		if assert.NotEmpty(t, written) {
+			assert.Equal(t, int64(100_000), written[len(written)-1])
+			assert.Equal(t, int64(100_000), total[0])
+		}
+	})
+
+	t.Run("UnknownSize", func(t *testing.T) {
+		buf := bytes.Buffer{}
+
+		encoder := newMultipartEncoder(&buf)
+
+		var written, total int64
+
+		file := NewInputFile("test.txt", io.MultiReader(strings.NewReader("bla-bla-bla"))).
+			WithProgress(func(w, t int64) {
+				written, total = w, t
+			})
+
+		assert.NoError(t, encoder.WriteFile("document", file))
+		assert.NoError(t, encoder.Close())
+
+		assert.Equal(t, int64(11), written)
+		assert.Equal(t, int64(-1), total)
+	})
+}
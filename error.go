@@ -1,7 +1,9 @@
 package tg
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
@@ -23,3 +25,238 @@ func (err *Error) Error() string {
 func (err *Error) Contains(v string) bool {
 	return strings.Contains(strings.ToLower(err.Message), v)
 }
+
+// Kind returns the class of the error, see [ClassifyError].
+func (err *Error) Kind() ErrorKind {
+	kind := ClassifyError(err.Code, err.Message)
+
+	if kind == ErrorKindUnknown && err.Parameters != nil && err.Parameters.MigrateToChatID != 0 {
+		return ErrorKindGroupMigrated
+	}
+
+	return kind
+}
+
+// Is reports whether the error is of the same kind as target sentinel error.
+// It allows to use [errors.Is] with sentinel errors like [ErrBotBlocked].
+func (err *Error) Is(target error) bool {
+	kind := err.Kind()
+	if kind == ErrorKindUnknown {
+		return false
+	}
+
+	return errorKindSentinels[kind] == target
+}
+
+// ErrorKind is a class of Telegram Bot API error.
+type ErrorKind int
+
+const (
+	// ErrorKindUnknown is an error which doesn't match any known kind.
+	ErrorKindUnknown ErrorKind = iota
+	// ErrorKindUnauthorized means the bot token is invalid or revoked.
+	ErrorKindUnauthorized
+	// ErrorKindBotBlocked means the bot was blocked by the user.
+	ErrorKindBotBlocked
+	// ErrorKindBotKicked means the bot was kicked from the group, supergroup or channel.
+	ErrorKindBotKicked
+	// ErrorKindUserDeactivated means the user account is deleted.
+	ErrorKindUserDeactivated
+	// ErrorKindChatNotFound means the chat doesn't exist or the bot has no access to it.
+	ErrorKindChatNotFound
+	// ErrorKindUserNotFound means the user doesn't exist or the bot has no access to it.
+	ErrorKindUserNotFound
+	// ErrorKindMessageNotModified means the edited message is the same as the current one.
+	ErrorKindMessageNotModified
+	// ErrorKindMessageToEditNotFound means the message to edit doesn't exist.
+	ErrorKindMessageToEditNotFound
+	// ErrorKindMessageToDeleteNotFound means the message to delete doesn't exist.
+	ErrorKindMessageToDeleteNotFound
+	// ErrorKindNotEnoughRights means the bot doesn't have enough rights to perform the action.
+	ErrorKindNotEnoughRights
+	// ErrorKindGroupMigrated means the group was upgraded to a supergroup.
+	// New chat identifier can be found in [ResponseParameters.MigrateToChatID].
+	ErrorKindGroupMigrated
+	// ErrorKindConflict means the request conflicts with another one, e.g. another getUpdates request or webhook is set.
+	ErrorKindConflict
+	// ErrorKindTooManyRequests means the bot exceeded flood limits.
+	// Retry delay can be found in [ResponseParameters.RetryAfter].
+	ErrorKindTooManyRequests
+)
+
+var errorKindNames = map[ErrorKind]string{
+	ErrorKindUnknown:                 "unknown",
+	ErrorKindUnauthorized:            "unauthorized",
+	ErrorKindBotBlocked:              "bot_blocked",
+	ErrorKindBotKicked:               "bot_kicked",
+	ErrorKindUserDeactivated:         "user_deactivated",
+	ErrorKindChatNotFound:            "chat_not_found",
+	ErrorKindUserNotFound:            "user_not_found",
+	ErrorKindMessageNotModified:      "message_not_modified",
+	ErrorKindMessageToEditNotFound:   "message_to_edit_not_found",
+	ErrorKindMessageToDeleteNotFound: "message_to_delete_not_found",
+	ErrorKindNotEnoughRights:         "not_enough_rights",
+	ErrorKindGroupMigrated:           "group_migrated",
+	ErrorKindConflict:                "conflict",
+	ErrorKindTooManyRequests:         "too_many_requests",
+}
+
+// String returns snake_case name of the error kind.
+func (kind ErrorKind) String() string {
+	if name, ok := errorKindNames[kind]; ok {
+		return name
+	}
+
+	return errorKindNames[ErrorKindUnknown]
+}
+
+// Sentinel errors for use with [errors.Is].
+//
+//	if errors.Is(err, tg.ErrMessageNotModified) {
+//	    return nil
+//	}
+var (
+	ErrUnauthorized            = errors.New("unauthorized")
+	ErrBotBlocked              = errors.New("bot was blocked by the user")
+	ErrBotKicked               = errors.New("bot was kicked from the chat")
+	ErrUserDeactivated         = errors.New("user is deactivated")
+	ErrChatNotFound            = errors.New("chat not found")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrMessageNotModified      = errors.New("message is not modified")
+	ErrMessageToEditNotFound   = errors.New("message to edit not found")
+	ErrMessageToDeleteNotFound = errors.New("message to delete not found")
+	ErrNotEnoughRights         = errors.New("not enough rights")
+	ErrGroupMigrated           = errors.New("group chat was upgraded to a supergroup chat")
+	ErrConflict                = errors.New("conflict")
+	ErrTooManyRequests         = errors.New("too many requests")
+)
+
+var errorKindSentinels = map[ErrorKind]error{
+	ErrorKindUnauthorized:            ErrUnauthorized,
+	ErrorKindBotBlocked:              ErrBotBlocked,
+	ErrorKindBotKicked:               ErrBotKicked,
+	ErrorKindUserDeactivated:         ErrUserDeactivated,
+	ErrorKindChatNotFound:            ErrChatNotFound,
+	ErrorKindUserNotFound:            ErrUserNotFound,
+	ErrorKindMessageNotModified:      ErrMessageNotModified,
+	ErrorKindMessageToEditNotFound:   ErrMessageToEditNotFound,
+	ErrorKindMessageToDeleteNotFound: ErrMessageToDeleteNotFound,
+	ErrorKindNotEnoughRights:         ErrNotEnoughRights,
+	ErrorKindGroupMigrated:           ErrGroupMigrated,
+	ErrorKindConflict:                ErrConflict,
+	ErrorKindTooManyRequests:         ErrTooManyRequests,
+}
+
+// errorKindPatterns maps lower-cased substrings of error description to error kinds.
+// Order matters, first match wins.
+var errorKindPatterns = []struct {
+	substr string
+	kind   ErrorKind
+}{
+	{"bot was blocked by the user", ErrorKindBotBlocked},
+	{"bot was kicked", ErrorKindBotKicked},
+	{"bot is not a member", ErrorKindBotKicked},
+	{"user is deactivated", ErrorKindUserDeactivated},
+	{"chat not found", ErrorKindChatNotFound},
+	{"user not found", ErrorKindUserNotFound},
+	{"message is not modified", ErrorKindMessageNotModified},
+	{"message to edit not found", ErrorKindMessageToEditNotFound},
+	{"message to delete not found", ErrorKindMessageToDeleteNotFound},
+	{"group chat was upgraded to a supergroup", ErrorKindGroupMigrated},
+	{"not enough rights", ErrorKindNotEnoughRights},
+	{"have no rights", ErrorKindNotEnoughRights},
+	{"need administrator rights", ErrorKindNotEnoughRights},
+}
+
+// ClassifyError maps Telegram Bot API error code and description to the error kind.
+// Returns [ErrorKindUnknown] if error is not recognized.
+func ClassifyError(code int, message string) ErrorKind {
+	switch code {
+	case http.StatusUnauthorized:
+		return ErrorKindUnauthorized
+	case http.StatusConflict:
+		return ErrorKindConflict
+	case http.StatusTooManyRequests:
+		return ErrorKindTooManyRequests
+	}
+
+	message = strings.ToLower(message)
+
+	for _, pattern := range errorKindPatterns {
+		if strings.Contains(message, pattern.substr) {
+			return pattern.kind
+		}
+	}
+
+	return ErrorKindUnknown
+}
+
+// httpErrorBodyLimit is the max length of body snippet stored in HTTPError.
+const httpErrorBodyLimit = 512
+
+// HTTPError is returned when the Bot API server responds with something that is not a Bot API response,
+// e.g. HTML error page from reverse proxy or empty body when local Bot API server is restarting.
+// It's distinguishable from [Error], which is returned by Bot API itself.
+type HTTPError struct {
+	// HTTP response status code.
+	StatusCode int
+
+	// Value of Content-Type header of the response.
+	ContentType string
+
+	// Beginning of the response body, truncated to 512 bytes.
+	Body []byte
+
+	// Optional. Error of response decoding.
+	Err error
+}
+
+func newHTTPError(res *http.Response, body []byte, err error) *HTTPError {
+	if len(body) > httpErrorBodyLimit {
+		body = body[:httpErrorBodyLimit]
+	}
+
+	return &HTTPError{
+		StatusCode:  res.StatusCode,
+		ContentType: res.Header.Get("Content-Type"),
+		Body:        body,
+		Err:         err,
+	}
+}
+
+func (err *HTTPError) Error() string {
+	result := fmt.Sprintf("unexpected http response %d", err.StatusCode)
+
+	if err.ContentType != "" {
+		result += fmt.Sprintf(" (%s)", err.ContentType)
+	}
+
+	if len(err.Body) > 0 {
+		result += fmt.Sprintf(": %q", err.Body)
+	}
+
+	if err.Err != nil {
+		result += fmt.Sprintf(": %v", err.Err)
+	}
+
+	return result
+}
+
+func (err *HTTPError) Unwrap() error {
+	return err.Err
+}
+
+// IsGateway reports whether the response is a gateway failure (502, 503 or 504),
+// which is usually temporary and the request can be retried.
+func (err *HTTPError) IsGateway() bool {
+	return isGatewayStatus(err.StatusCode)
+}
+
+func isGatewayStatus(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
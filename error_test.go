@@ -1,6 +1,8 @@
 package tg
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -38,3 +40,83 @@ func TestError_Contains(t *testing.T) {
 
 	assert.False(t, err.Contains("Test"))
 }
+
+func TestClassifyError(t *testing.T) {
+	for _, test := range []struct {
+		Code    int
+		Message string
+		Kind    ErrorKind
+	}{
+		{401, "Unauthorized", ErrorKindUnauthorized},
+		{403, "Forbidden: bot was blocked by the user", ErrorKindBotBlocked},
+		{403, "Forbidden: bot was kicked from the supergroup chat", ErrorKindBotKicked},
+		{403, "Forbidden: bot is not a member of the channel chat", ErrorKindBotKicked},
+		{403, "Forbidden: user is deactivated", ErrorKindUserDeactivated},
+		{400, "Bad Request: chat not found", ErrorKindChatNotFound},
+		{400, "Bad Request: user not found", ErrorKindUserNotFound},
+		{400, "Bad Request: message is not modified: specified new message content and reply markup are exactly the same", ErrorKindMessageNotModified},
+		{400, "Bad Request: message to edit not found", ErrorKindMessageToEditNotFound},
+		{400, "Bad Request: message to delete not found", ErrorKindMessageToDeleteNotFound},
+		{400, "Bad Request: not enough rights to send text messages to the chat", ErrorKindNotEnoughRights},
+		{403, "Forbidden: bot have no rights to send a message", ErrorKindNotEnoughRights},
+		{400, "Bad Request: group chat was upgraded to a supergroup chat", ErrorKindGroupMigrated},
+		{409, "Conflict: terminated by other getUpdates request", ErrorKindConflict},
+		{429, "Too Many Requests: retry after 5", ErrorKindTooManyRequests},
+		{400, "Bad Request: message text is empty", ErrorKindUnknown},
+	} {
+		assert.Equal(t, test.Kind, ClassifyError(test.Code, test.Message), test.Message)
+	}
+}
+
+func TestError_Kind(t *testing.T) {
+	t.Run("Message", func(t *testing.T) {
+		err := &Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+
+		assert.Equal(t, ErrorKindBotBlocked, err.Kind())
+	})
+
+	t.Run("MigrateParameters", func(t *testing.T) {
+		err := &Error{
+			Code:       400,
+			Message:    "Bad Request: unknown",
+			Parameters: &ResponseParameters{MigrateToChatID: 12345},
+		}
+
+		assert.Equal(t, ErrorKindGroupMigrated, err.Kind())
+	})
+}
+
+func TestError_Is(t *testing.T) {
+	var err error = fmt.Errorf("send message: %w", &Error{
+		Code:    400,
+		Message: "Bad Request: message is not modified",
+	})
+
+	assert.ErrorIs(t, err, ErrMessageNotModified)
+	assert.NotErrorIs(t, err, ErrBotBlocked)
+	assert.NotErrorIs(t, &Error{Code: 400, Message: "Bad Request: unknown"}, ErrBotBlocked)
+}
+
+func TestErrorKind_String(t *testing.T) {
+	assert.Equal(t, "bot_blocked", ErrorKindBotBlocked.String())
+	assert.Equal(t, "unknown", ErrorKind(-1).String())
+}
+
+func TestHTTPError_Error(t *testing.T) {
+	t.Run("Full", func(t *testing.T) {
+		err := &HTTPError{
+			StatusCode:  502,
+			ContentType: "text/html",
+			Body:        []byte("<html>"),
+			Err:         errors.New("test"),
+		}
+
+		assert.EqualError(t, err, `unexpected http response 502 (text/html): "<html>": test`)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		err := &HTTPError{StatusCode: 503}
+
+		assert.EqualError(t, err, "unexpected http response 503")
+	})
+}
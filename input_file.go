@@ -2,7 +2,10 @@ package tg
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -19,8 +22,28 @@ type InputFile struct {
 	Body io.Reader
 
 	addr string
+
+	// open opens body of file again,
+	// used to rewind body which is not io.Seeker.
+	open func() (io.Reader, error)
+
+	// path is absolute path of file on local disk,
+	// used to pass file by URI to local Bot API server.
+	path string
+
+	progress InputFileProgress
 }
 
+// InputFileProgress is a callback for reporting upload progress of InputFile.
+// It's called each time a chunk of the file is written to request body.
+// Written is number of bytes uploaded so far, total is size of the file or -1 if it's unknown.
+type InputFileProgress func(written, total int64)
+
+// ErrInputFileNotRewindable is returned when the file should be uploaded again (e.g. request is retried),
+// but its body can't be read from the beginning.
+// Use body implementing io.Seeker, like [NewInputFileBytes], [NewInputFileLocal] and [NewInputFileFS] do.
+var ErrInputFileNotRewindable = errors.New("input file body is not rewindable")
+
 func (file *InputFile) MarshalJSON() ([]byte, error) {
 	if file.addr != "" {
 		return json.Marshal(file.addr)
@@ -35,6 +58,34 @@ func (file InputFile) WithName(name string) InputFile {
 	return file
 }
 
+// WithProgress creates new InputFile with upload progress callback.
+//
+// Example:
+//
+//	file = file.WithProgress(func(written, total int64) {
+//	    log.Printf("uploaded %d of %d bytes", written, total)
+//	})
+func (file InputFile) WithProgress(progress InputFileProgress) InputFile {
+	file.progress = progress
+	return file
+}
+
+// size returns size of file body if it can be determined without reading, -1 otherwise.
+func (file *InputFile) size() int64 {
+	switch body := file.Body.(type) {
+	case interface{ Len() int }:
+		return int64(body.Len())
+	case interface{ Stat() (fs.FileInfo, error) }:
+		info, err := body.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		return info.Size()
+	default:
+		return -1
+	}
+}
+
 // Ptr returns pointer to InputFile. Helper method.
 func (file InputFile) Ptr() *InputFile {
 	return &file
@@ -49,7 +100,66 @@ func (file InputFile) Close() error {
 	return nil
 }
 
+// rewind resets body of the file to the beginning, so it can be read again.
+func (file *InputFile) rewind() error {
+	if seeker, ok := file.Body.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("seek: %w", err)
+		}
+		return nil
+	}
+
+	if file.open != nil {
+		_ = file.Close()
+
+		body, err := file.open()
+		if err != nil {
+			return fmt.Errorf("reopen: %w", err)
+		}
+
+		file.Body = body
+
+		return nil
+	}
+
+	return ErrInputFileNotRewindable
+}
+
+// isRewindable reports whether the body of file can be read again.
+func (file *InputFile) isRewindable() bool {
+	_, ok := file.Body.(io.Seeker)
+	return ok || file.open != nil
+}
+
+// digest reads the body of file and returns hex encoded sha256 of content.
+// If consumed is true, the body could be read by previous upload attempt and it's rewound before reading.
+// Body is rewound after reading, so the file can be uploaded later.
+func (file *InputFile) digest(consumed bool) (string, error) {
+	if !file.isRewindable() {
+		return "", ErrInputFileNotRewindable
+	}
+
+	if consumed {
+		if err := file.rewind(); err != nil {
+			return "", err
+		}
+	}
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file.Body); err != nil {
+		return "", fmt.Errorf("read: %w", err)
+	}
+
+	if err := file.rewind(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 // NewInputFile creates new InputFile with given name and body.
+// If body implements io.Seeker, the file can be uploaded again on retry of request.
 func NewInputFile(name string, body io.Reader) InputFile {
 	return InputFile{
 		Name: name,
@@ -70,6 +180,9 @@ func NewInputFileBytes(name string, body []byte) InputFile {
 // This method just open file by provided path.
 // So, you should close it AFTER send.
 //
+// If Client works with local Bot API server (see [WithClientLocalMode]),
+// the file is not uploaded, but passed by file:// URI.
+//
 // Example:
 //
 //	file, err := NewInputFileLocal("test.png")
@@ -83,13 +196,24 @@ func NewInputFileLocal(path string) (InputFile, error) {
 		return InputFile{}, err
 	}
 
-	return NewInputFile(
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		_ = file.Close()
+		return InputFile{}, fmt.Errorf("absolute path: %w", err)
+	}
+
+	result := NewInputFile(
 		filepath.Base(path),
 		file,
-	), nil
+	)
+
+	result.path = abs
+
+	return result, nil
 }
 
 // NewInputFileFS creates the InputFile from provided FS and file path.
+// If file doesn't implement io.Seeker, it will be opened again on retry of request.
 //
 // Usage:
 //
@@ -106,8 +230,14 @@ func NewInputFileFS(fsys fs.FS, path string) (InputFile, error) {
 		return InputFile{}, fmt.Errorf("open file: %w", err)
 	}
 
-	return NewInputFile(
+	result := NewInputFile(
 		filepath.Base(path),
 		file,
-	), nil
+	)
+
+	result.open = func() (io.Reader, error) {
+		return fsys.Open(path)
+	}
+
+	return result, nil
 }
@@ -2,7 +2,9 @@ package tg
 
 import (
 	"encoding/json"
+	"io"
 	"io/fs"
+	"os"
 	"strings"
 	"testing"
 	"testing/fstest"
@@ -113,3 +115,28 @@ func TestInputFile_Ptr(t *testing.T) {
 
 	assert.Equal(t, &file, file.Ptr())
 }
+
+func TestInputFile_size(t *testing.T) {
+	t.Run("Bytes", func(t *testing.T) {
+		file := NewInputFileBytes("test.txt", []byte("test"))
+
+		assert.Equal(t, int64(4), file.size())
+	})
+
+	t.Run("Local", func(t *testing.T) {
+		file, err := NewInputFileLocal("_examples/echo-bot/resources/gopher.png")
+		require.NoError(t, err)
+		defer file.Close()
+
+		info, err := os.Stat("_examples/echo-bot/resources/gopher.png")
+		require.NoError(t, err)
+
+		assert.Equal(t, info.Size(), file.size())
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		file := NewInputFile("test.txt", io.MultiReader(strings.NewReader("test")))
+
+		assert.Equal(t, int64(-1), file.size())
+	})
+}
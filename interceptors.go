@@ -3,9 +3,9 @@ package tg
 import (
 	"context"
 	"errors"
-	"math"
-	"math/rand"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -52,6 +52,8 @@ func WithInterceptorRetryFloodErrorTimeAfter(timeAfter func(time.Duration) <-cha
 // The interceptor will retry the request if the error is flood error with RetryAfter less than maxRetryAfter.
 // The interceptor will wait for RetryAfter duration before retrying the request.
 // The interceptor will retry the request for tries times.
+//
+// It's a shortcut for [NewInterceptorRetry] with [RetryOnFlood] policy.
 func NewInterceptorRetryFloodError(opts ...InterceptorRetryFloodErrorOption) Interceptor {
 	options := interceptorRetryFloodErrorOpts{
 		tries:         3,
@@ -63,34 +65,11 @@ func NewInterceptorRetryFloodError(opts ...InterceptorRetryFloodErrorOption) Int
 		o(&options)
 	}
 
-	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
-		var err error
-	LOOP:
-		for i := 0; i < options.tries; i++ {
-			err = invoker(ctx, req, dst)
-			if err == nil {
-				return nil
-			}
-
-			var tgErr *Error
-			if errors.As(err, &tgErr) && tgErr.Code == http.StatusTooManyRequests && tgErr.Parameters != nil {
-				if tgErr.Parameters.RetryAfterDuration() > options.maxRetryAfter {
-					return err
-				}
-
-				select {
-				case <-options.timeAfter(tgErr.Parameters.RetryAfterDuration()):
-					continue LOOP
-				case <-ctx.Done():
-					return ctx.Err()
-				}
-			}
-
-			break
-		}
-
-		return err
-	}
+	return NewInterceptorRetry(
+		WithInterceptorRetryTries(options.tries),
+		WithInterceptorRetryPolicy(RetryOnFlood(options.maxRetryAfter)),
+		WithInterceptorRetryTimeAfter(options.timeAfter),
+	)
 }
 
 type interceptorRetryInternalServerErrorOpts struct {
@@ -124,7 +103,24 @@ func WithInterceptorRetryInternalServerErrorTimeAfter(timeAfter func(time.Durati
 	}
 }
 
+// isServerError reports whether the error is internal server error of Bot API
+// or gateway failure of HTTP server in front of it.
+func isServerError(err error) bool {
+	var tgErr *Error
+	if errors.As(err, &tgErr) {
+		return tgErr.Code == http.StatusInternalServerError || isGatewayStatus(tgErr.Code)
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusInternalServerError || httpErr.IsGateway()
+	}
+
+	return false
+}
+
 // NewInterceptorRetryInternalServerError returns a new interceptor that retries the request if the error is internal server error.
+// Gateway failures (502, 503 and 504) of HTTP server in front of Bot API, see [HTTPError], are retried too.
 //
 // With that interceptor, calling of method that hit limit will be look like it will look like the request just takes unusually long.
 // Under the hood, multiple HTTP requests are being performed, with the appropriate delays in between.
@@ -133,6 +129,8 @@ func WithInterceptorRetryInternalServerErrorTimeAfter(timeAfter func(time.Durati
 // The interceptor will retry the request if the error is internal server error.
 // The interceptor will wait for delay * 2^i + random jitter before retrying the request, where i is the number of tries.
 // The interceptor will retry the request for ten times.
+//
+// It's a shortcut for [NewInterceptorRetry] with [RetryOnServerError] policy and [RetryBackoffExponential].
 func NewInterceptorRetryInternalServerError(opts ...RetryInternalServerErrorOption) Interceptor {
 	options := &interceptorRetryInternalServerErrorOpts{
 		tries:     10,
@@ -144,33 +142,84 @@ func NewInterceptorRetryInternalServerError(opts ...RetryInternalServerErrorOpti
 		o(options)
 	}
 
+	return NewInterceptorRetry(
+		WithInterceptorRetryTries(options.tries),
+		WithInterceptorRetryPolicy(RetryOnServerError()),
+		WithInterceptorRetryBackoff(RetryBackoffExponential(options.delay, 0)),
+		WithInterceptorRetryTimeAfter(options.timeAfter),
+	)
+}
+
+type interceptorChatMigrationOpts struct {
+	hook func(ctx context.Context, from, to ChatID) error
+}
+
+// InterceptorChatMigrationOption is an option for NewInterceptorChatMigration.
+type InterceptorChatMigrationOption func(*interceptorChatMigrationOpts)
+
+// WithInterceptorChatMigrationHook sets the function called when group migration is detected.
+// It can be used to persist old to new chat id mapping, e.g. update ids in database or session store.
+// If hook returns an error, the request is not retried and the error is returned.
+func WithInterceptorChatMigrationHook(hook func(ctx context.Context, from, to ChatID) error) InterceptorChatMigrationOption {
+	return func(o *interceptorChatMigrationOpts) {
+		o.hook = hook
+	}
+}
+
+// NewInterceptorChatMigration returns a new interceptor that handles group to supergroup migration.
+//
+// When a group is upgraded to a supergroup, Telegram rejects requests to the old chat
+// and returns the new chat id in [ResponseParameters.MigrateToChatID].
+// The interceptor calls the hook (if any), rewrites the chat_id argument of the request
+// and transparently retries the request once.
+//
+// Requests with from_chat_id argument (forwardMessage, copyMessage, etc.) are not handled,
+// because the error doesn't tell which of the chats was migrated.
+func NewInterceptorChatMigration(opts ...InterceptorChatMigrationOption) Interceptor {
+	options := &interceptorChatMigrationOpts{}
+
+	for _, o := range opts {
+		o(options)
+	}
+
 	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
-		var err error
-	LOOP:
-		for i := 0; i < options.tries; i++ {
-			err = invoker(ctx, req, dst)
-			if err == nil {
-				return nil
-			}
+		err := invoker(ctx, req, dst)
+		if err == nil {
+			return nil
+		}
 
-			var tgErr *Error
-			if errors.As(err, &tgErr) && tgErr.Code == http.StatusInternalServerError {
-				// do backoff delay
-				backoffDelay := options.delay * time.Duration(math.Pow(2, float64(i)))
-				jitter := time.Duration(rand.Int63n(int64(backoffDelay)))
-
-				select {
-				case <-options.timeAfter(backoffDelay + jitter):
-					continue LOOP
-				case <-ctx.Done():
-					return ctx.Err()
-				}
-			}
+		var tgErr *Error
+		if !errors.As(err, &tgErr) || tgErr.Parameters == nil || tgErr.Parameters.MigrateToChatID == 0 {
+			return err
+		}
 
-			break
+		if req.Has("from_chat_id") {
+			return err
 		}
 
-		return err
+		chatID, ok := req.GetArg("chat_id")
+		if !ok {
+			return err
+		}
+
+		from, parseErr := strconv.ParseInt(chatID, 10, 64)
+		if parseErr != nil {
+			return err
+		}
+
+		to := tgErr.Parameters.MigrateToChatID
+
+		if options.hook != nil {
+			if err := options.hook(ctx, ChatID(from), to); err != nil {
+				return fmt.Errorf("chat migration hook: %w", err)
+			}
+		}
+
+		req.ChatID("chat_id", to)
+
+		countRetry(ctx)
+
+		return invoker(ctx, req, dst)
 	}
 }
 
@@ -0,0 +1,237 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditLogEntry is a line of audit log written by [AuditLog].
+type AuditLogEntry struct {
+	// Time when the request was started.
+	Time time.Time `json:"time"`
+
+	// Duration of the request in milliseconds.
+	DurationMS int64 `json:"duration_ms"`
+
+	// Request snapshot, files are described by name, size and SHA-256 of content.
+	Request *RequestRecord `json:"request"`
+
+	// Optional. Raw result of successful request as returned by Bot API.
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Optional. Error of failed request.
+	Error string `json:"error,omitempty"`
+
+	// Optional. Error code of Bot API, see [Error].
+	ErrorCode int `json:"error_code,omitempty"`
+}
+
+type auditLogOpts struct {
+	filter  func(req *Request) bool
+	onError func(ctx context.Context, err error)
+	now     func() time.Time
+}
+
+// AuditLogOption is an option for NewAuditLog.
+type AuditLogOption func(*auditLogOpts)
+
+// WithAuditLogFilter sets the function that decides which requests are logged.
+// By default all requests are logged.
+func WithAuditLogFilter(filter func(req *Request) bool) AuditLogOption {
+	return func(o *auditLogOpts) {
+		o.filter = filter
+	}
+}
+
+// WithAuditLogMethods logs only requests of specified methods.
+func WithAuditLogMethods(methods ...string) AuditLogOption {
+	set := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		set[method] = struct{}{}
+	}
+
+	return WithAuditLogFilter(func(req *Request) bool {
+		_, ok := set[req.Method]
+		return ok
+	})
+}
+
+// WithAuditLogOnError sets the function called when the entry can't be recorded or written.
+// Audit log doesn't fail requests, by default such errors are ignored.
+func WithAuditLogOnError(onError func(ctx context.Context, err error)) AuditLogOption {
+	return func(o *auditLogOpts) {
+		o.onError = onError
+	}
+}
+
+// WithAuditLogNow sets the time.Now function.
+func WithAuditLogNow(now func() time.Time) AuditLogOption {
+	return func(o *auditLogOpts) {
+		o.now = now
+	}
+}
+
+// AuditLog writes requests and their results to [io.Writer] in JSON Lines format, see [AuditLogEntry].
+//
+// Use [AuditLog.Intercept] as interceptor. Register it last, so it records the request as it's sent to Bot API
+// (e.g. after [NewInterceptorUploadCache] replaced files) and each try of retried requests:
+//
+//	audit := tg.NewAuditLog(file, tg.WithAuditLogFilter(func(req *tg.Request) bool {
+//	  return tg.IsSendMethod(req.Method)
+//	}))
+//
+//	client := tg.New(token,
+//	  tg.WithClientInterceptors(
+//	    tg.NewInterceptorRetryFloodError(),
+//	    audit.Intercept,
+//	  ),
+//	)
+//
+// Interceptors registered after it get [json.RawMessage] as result destination.
+type AuditLog struct {
+	options *auditLogOpts
+
+	lock sync.Mutex
+	w    io.Writer
+}
+
+// NewAuditLog creates a new audit log writing to w.
+func NewAuditLog(w io.Writer, opts ...AuditLogOption) *AuditLog {
+	options := &auditLogOpts{
+		filter:  func(req *Request) bool { return true },
+		onError: func(ctx context.Context, err error) {},
+		now:     time.Now,
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	return &AuditLog{
+		options: options,
+		w:       w,
+	}
+}
+
+// Rotate replaces the writer and returns the previous one, so it can be closed.
+// Entries are written entirely to one of writers.
+//
+//	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+//	if err != nil {
+//	  return err
+//	}
+//
+//	return audit.Rotate(file).(io.Closer).Close()
+func (log *AuditLog) Rotate(w io.Writer) io.Writer {
+	log.lock.Lock()
+	defer log.lock.Unlock()
+
+	prev := log.w
+	log.w = w
+
+	return prev
+}
+
+// Intercept implements [Interceptor].
+func (log *AuditLog) Intercept(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+	if !log.options.filter(req) {
+		return invoker(ctx, req, dst)
+	}
+
+	record, err := log.record(req)
+	if err != nil {
+		log.options.onError(ctx, fmt.Errorf("audit log: record request: %w", err))
+		return invoker(ctx, req, dst)
+	}
+
+	entry := &AuditLogEntry{
+		Time:    log.options.now(),
+		Request: record,
+	}
+
+	var result json.RawMessage
+
+	err = invoker(ctx, req, &result)
+
+	entry.DurationMS = log.options.now().Sub(entry.Time).Milliseconds()
+
+	if err == nil && dst != nil && result != nil {
+		if unmarshalErr := json.Unmarshal(result, dst); unmarshalErr != nil {
+			err = fmt.Errorf("unmarshal: %w", unmarshalErr)
+		}
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+
+		var tgErr *Error
+		if errors.As(err, &tgErr) {
+			entry.ErrorCode = tgErr.Code
+		}
+	} else {
+		entry.Result = result
+	}
+
+	if writeErr := log.write(entry); writeErr != nil {
+		log.options.onError(ctx, fmt.Errorf("audit log: %w", writeErr))
+	}
+
+	return err
+}
+
+// record returns the snapshot of request with digests of files.
+// Files which can't be read without consuming are recorded without digest.
+func (log *AuditLog) record(req *Request) (*RequestRecord, error) {
+	sums := make(map[string]string, len(req.files))
+
+	for name, file := range req.files {
+		if !file.isRewindable() {
+			continue
+		}
+
+		sum, err := file.digest(req.filesConsumed)
+		req.files[name] = file
+		if err != nil {
+			return nil, fmt.Errorf("digest of %s: %w", name, err)
+		}
+
+		sums[name] = sum
+	}
+
+	// files are rewound by digest, so their size is known
+	record, err := req.Record()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, sum := range sums {
+		file := record.Files[name]
+		file.SHA256 = sum
+		record.Files[name] = file
+	}
+
+	return record, nil
+}
+
+func (log *AuditLog) write(entry *AuditLogEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal entry: %w", err)
+	}
+
+	data = append(data, '\n')
+
+	log.lock.Lock()
+	defer log.lock.Unlock()
+
+	if _, err := log.w.Write(data); err != nil {
+		return fmt.Errorf("write entry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package tg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	newAuditLog := func(buf *bytes.Buffer, opts ...AuditLogOption) *AuditLog {
+		return NewAuditLog(buf, append([]AuditLogOption{
+			WithAuditLogNow(func() time.Time { return now }),
+		}, opts...)...)
+	}
+
+	respond := func(result string) InterceptorInvoker {
+		return func(ctx context.Context, req *Request, dst any) error {
+			return json.Unmarshal([]byte(result), dst)
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		audit := newAuditLog(buf)
+
+		req := NewRequest("sendDocument").
+			ChatID("chat_id", 1).
+			JSON("reply_markup", map[string]any{"remove_keyboard": true}).
+			InputFile("document", NewInputFileBytes("test.txt", []byte("test")))
+
+		var msg Message
+
+		err := audit.Intercept(context.Background(), req, &msg, respond(`{"message_id":10,"date":1,"chat":{"id":1,"type":"private"}}`))
+		require.NoError(t, err)
+		assert.Equal(t, 10, msg.ID, "should decode result to destination")
+
+		assert.JSONEq(t, `{
+			"time": "2024-01-02T03:04:05Z",
+			"duration_ms": 0,
+			"request": {
+				"method": "sendDocument",
+				"args": {"chat_id": "1", "reply_markup": "{\"remove_keyboard\":true}"},
+				"files": {"document": {
+					"name": "test.txt",
+					"size": 4,
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+				}}
+			},
+			"result": {"message_id":10,"date":1,"chat":{"id":1,"type":"private"}}
+		}`, buf.String())
+		assert.True(t, strings.HasSuffix(buf.String(), "}\n"), "should be JSON line")
+
+		data, err := readAllFile(req.files["document"])
+		require.NoError(t, err)
+		assert.Equal(t, "test", data, "file should be rewound")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		audit := newAuditLog(buf)
+
+		err := audit.Intercept(context.Background(), NewRequest("sendMessage").ChatID("chat_id", 1), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				return &Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+			},
+		)
+		require.ErrorIs(t, err, ErrBotBlocked)
+
+		var entry AuditLogEntry
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "403: Forbidden: bot was blocked by the user", entry.Error)
+		assert.Equal(t, 403, entry.ErrorCode)
+		assert.Empty(t, entry.Result)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		audit := newAuditLog(buf, WithAuditLogMethods("sendMessage"))
+
+		var user User
+
+		err := audit.Intercept(context.Background(), NewRequest("getMe"), &user, respond(`{"id":1}`))
+		require.NoError(t, err)
+		assert.Equal(t, UserID(1), user.ID)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		first, second := &bytes.Buffer{}, &bytes.Buffer{}
+
+		audit := newAuditLog(first)
+
+		require.NoError(t, audit.Intercept(context.Background(), NewRequest("sendMessage"), nil, respond(`true`)))
+
+		prev := audit.Rotate(second)
+		assert.Same(t, first, prev)
+
+		require.NoError(t, audit.Intercept(context.Background(), NewRequest("sendMessage"), nil, respond(`true`)))
+
+		assert.Equal(t, 1, strings.Count(first.String(), "\n"))
+		assert.Equal(t, 1, strings.Count(second.String(), "\n"))
+	})
+
+	t.Run("WriteError", func(t *testing.T) {
+		var reported error
+
+		audit := NewAuditLog(errorWriter{}, WithAuditLogOnError(func(ctx context.Context, err error) {
+			reported = err
+		}))
+
+		err := audit.Intercept(context.Background(), NewRequest("sendMessage"), nil, respond(`true`))
+		require.NoError(t, err, "should not fail request")
+		assert.ErrorContains(t, reported, "write entry")
+	})
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk is full")
+}
+
+func readAllFile(file InputFile) (string, error) {
+	var buf bytes.Buffer
+	_, err := buf.ReadFrom(file.Body)
+	return buf.String(), err
+}
@@ -0,0 +1,293 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerState is a state of [CircuitBreaker].
+type CircuitBreakerState int
+
+const (
+	// CircuitBreakerClosed means requests are passed to Bot API.
+	CircuitBreakerClosed CircuitBreakerState = iota
+	// CircuitBreakerOpen means requests fail fast with [CircuitOpenError].
+	CircuitBreakerOpen
+	// CircuitBreakerHalfOpen means limited number of probe requests are passed to check if Bot API is recovered.
+	CircuitBreakerHalfOpen
+)
+
+var circuitBreakerStateNames = map[CircuitBreakerState]string{
+	CircuitBreakerClosed:   "closed",
+	CircuitBreakerOpen:     "open",
+	CircuitBreakerHalfOpen: "half_open",
+}
+
+// String returns snake_case name of the state.
+func (state CircuitBreakerState) String() string {
+	if name, ok := circuitBreakerStateNames[state]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("CircuitBreakerState(%d)", int(state))
+}
+
+// ErrCircuitOpen is a sentinel error for use with [errors.Is], matched by [CircuitOpenError].
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned by [CircuitBreaker] without calling Bot API while circuit is open.
+type CircuitOpenError struct {
+	// RetryAfter is the time left until the circuit becomes half-open.
+	// Zero means the circuit is half-open and probe requests are in flight.
+	RetryAfter time.Duration
+}
+
+func (err *CircuitOpenError) Error() string {
+	if err.RetryAfter > 0 {
+		return fmt.Sprintf("%v, retry after %v", ErrCircuitOpen, err.RetryAfter)
+	}
+
+	return ErrCircuitOpen.Error()
+}
+
+func (err *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+type circuitBreakerOpts struct {
+	threshold     int
+	openTimeout   time.Duration
+	halfOpenLimit int
+	isFailure     func(err error) bool
+	onStateChange func(from, to CircuitBreakerState)
+	now           func() time.Time
+}
+
+// CircuitBreakerOption is an option for NewCircuitBreaker.
+type CircuitBreakerOption func(*circuitBreakerOpts)
+
+// WithCircuitBreakerThreshold sets the number of consecutive failures which opens the circuit.
+// Default is 5.
+func WithCircuitBreakerThreshold(threshold int) CircuitBreakerOption {
+	return func(o *circuitBreakerOpts) {
+		o.threshold = threshold
+	}
+}
+
+// WithCircuitBreakerOpenTimeout sets how long the circuit stays open before probing.
+// Default is 30 seconds.
+func WithCircuitBreakerOpenTimeout(timeout time.Duration) CircuitBreakerOption {
+	return func(o *circuitBreakerOpts) {
+		o.openTimeout = timeout
+	}
+}
+
+// WithCircuitBreakerHalfOpenRequests sets the number of concurrent probe requests in half-open state.
+// Default is 1.
+func WithCircuitBreakerHalfOpenRequests(n int) CircuitBreakerOption {
+	return func(o *circuitBreakerOpts) {
+		o.halfOpenLimit = n
+	}
+}
+
+// WithCircuitBreakerIsFailure sets the function that decides which errors are failures of Bot API.
+// Default counts network errors, timeouts and server errors (500, 502, 503 and 504).
+// Other errors, like [ErrBotBlocked], mean Bot API is available.
+func WithCircuitBreakerIsFailure(isFailure func(err error) bool) CircuitBreakerOption {
+	return func(o *circuitBreakerOpts) {
+		o.isFailure = isFailure
+	}
+}
+
+// WithCircuitBreakerOnStateChange sets the function called on each state change.
+// It's called synchronously with the breaker locked, so it should not block or call the breaker methods.
+func WithCircuitBreakerOnStateChange(onStateChange func(from, to CircuitBreakerState)) CircuitBreakerOption {
+	return func(o *circuitBreakerOpts) {
+		o.onStateChange = onStateChange
+	}
+}
+
+// WithCircuitBreakerNow sets the time.Now function.
+func WithCircuitBreakerNow(now func() time.Time) CircuitBreakerOption {
+	return func(o *circuitBreakerOpts) {
+		o.now = now
+	}
+}
+
+// CircuitBreaker stops calling Bot API during outages.
+//
+// The circuit opens after threshold consecutive failures, while it's open requests fail fast with [CircuitOpenError].
+// After open timeout the circuit becomes half-open and lets probe requests through:
+// successful probe closes the circuit, failed one opens it again.
+//
+// Use [CircuitBreaker.Intercept] as interceptor:
+//
+//	breaker := tg.NewCircuitBreaker()
+//
+//	client := tg.New(token,
+//	  tg.WithClientInterceptors(breaker.Intercept),
+//	)
+type CircuitBreaker struct {
+	options *circuitBreakerOpts
+
+	lock     sync.Mutex
+	state    CircuitBreakerState
+	failures int
+	openedAt time.Time
+	probes   int
+
+	// generation is incremented each time the circuit opens,
+	// so results of probes from previous half-open period are ignored.
+	generation int
+}
+
+// NewCircuitBreaker creates a new circuit breaker in closed state.
+func NewCircuitBreaker(opts ...CircuitBreakerOption) *CircuitBreaker {
+	options := &circuitBreakerOpts{
+		threshold:     5,
+		openTimeout:   30 * time.Second,
+		halfOpenLimit: 1,
+		isFailure: func(err error) bool {
+			return isServerError(err) || isNetworkError(err) || isTimeoutError(err)
+		},
+		onStateChange: func(from, to CircuitBreakerState) {},
+		now:           time.Now,
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	return &CircuitBreaker{
+		options: options,
+	}
+}
+
+// State returns the current state of the circuit.
+func (breaker *CircuitBreaker) State() CircuitBreakerState {
+	breaker.lock.Lock()
+	defer breaker.lock.Unlock()
+
+	if breaker.state == CircuitBreakerOpen && breaker.openTimeoutLeft() <= 0 {
+		return CircuitBreakerHalfOpen
+	}
+
+	return breaker.state
+}
+
+// Intercept implements [Interceptor].
+func (breaker *CircuitBreaker) Intercept(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+	probe, err := breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = invoker(ctx, req, dst)
+
+	// request is canceled by caller, it says nothing about Bot API
+	if err != nil && ctx.Err() != nil {
+		breaker.cancel(probe)
+		return err
+	}
+
+	breaker.done(probe, err != nil && breaker.options.isFailure(err))
+
+	return err
+}
+
+// openTimeoutLeft returns the time left until the open circuit becomes half-open.
+func (breaker *CircuitBreaker) openTimeoutLeft() time.Duration {
+	return breaker.openedAt.Add(breaker.options.openTimeout).Sub(breaker.options.now())
+}
+
+// allow checks if request can be passed.
+// Probe is generation of half-open circuit if it's probe request, -1 otherwise.
+func (breaker *CircuitBreaker) allow() (probe int, err error) {
+	breaker.lock.Lock()
+	defer breaker.lock.Unlock()
+
+	switch breaker.state {
+	case CircuitBreakerOpen:
+		if left := breaker.openTimeoutLeft(); left > 0 {
+			return -1, &CircuitOpenError{RetryAfter: left}
+		}
+
+		breaker.setState(CircuitBreakerHalfOpen)
+
+		fallthrough
+	case CircuitBreakerHalfOpen:
+		if breaker.probes >= breaker.options.halfOpenLimit {
+			return -1, &CircuitOpenError{}
+		}
+
+		breaker.probes++
+
+		return breaker.generation, nil
+	default:
+		return -1, nil
+	}
+}
+
+// done records result of the request.
+func (breaker *CircuitBreaker) done(probe int, failure bool) {
+	breaker.lock.Lock()
+	defer breaker.lock.Unlock()
+
+	switch {
+	case breaker.isProbe(probe):
+		breaker.probes--
+
+		if failure {
+			breaker.open()
+		} else {
+			breaker.failures = 0
+			breaker.setState(CircuitBreakerClosed)
+		}
+	case probe < 0 && breaker.state == CircuitBreakerClosed:
+		if !failure {
+			breaker.failures = 0
+			return
+		}
+
+		breaker.failures++
+
+		if breaker.failures >= breaker.options.threshold {
+			breaker.open()
+		}
+	}
+}
+
+// cancel releases probe slot of the request canceled by caller.
+func (breaker *CircuitBreaker) cancel(probe int) {
+	breaker.lock.Lock()
+	defer breaker.lock.Unlock()
+
+	if breaker.isProbe(probe) {
+		breaker.probes--
+	}
+}
+
+// isProbe reports whether the request is probe of the current half-open period.
+func (breaker *CircuitBreaker) isProbe(probe int) bool {
+	return probe >= 0 && probe == breaker.generation && breaker.state == CircuitBreakerHalfOpen
+}
+
+func (breaker *CircuitBreaker) open() {
+	breaker.openedAt = breaker.options.now()
+	breaker.probes = 0
+	breaker.generation++
+	breaker.setState(CircuitBreakerOpen)
+}
+
+func (breaker *CircuitBreaker) setState(state CircuitBreakerState) {
+	if breaker.state == state {
+		return
+	}
+
+	from := breaker.state
+	breaker.state = state
+	breaker.options.onStateChange(from, state)
+}
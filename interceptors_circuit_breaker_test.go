@@ -0,0 +1,217 @@
+package tg
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	type transition struct{ from, to CircuitBreakerState }
+
+	newBreaker := func(now *time.Time, transitions *[]transition, opts ...CircuitBreakerOption) *CircuitBreaker {
+		return NewCircuitBreaker(append([]CircuitBreakerOption{
+			WithCircuitBreakerThreshold(3),
+			WithCircuitBreakerOpenTimeout(time.Minute),
+			WithCircuitBreakerNow(func() time.Time { return *now }),
+			WithCircuitBreakerOnStateChange(func(from, to CircuitBreakerState) {
+				*transitions = append(*transitions, transition{from, to})
+			}),
+		}, opts...)...)
+	}
+
+	call := func(breaker *CircuitBreaker, err error) (bool, error) {
+		called := false
+		result := breaker.Intercept(context.Background(), NewRequest("getMe"), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				called = true
+				return err
+			},
+		)
+		return called, result
+	}
+
+	t.Run("Open", func(t *testing.T) {
+		now := time.Now()
+		var transitions []transition
+
+		breaker := newBreaker(&now, &transitions)
+
+		for i := 0; i < 2; i++ {
+			_, err := call(breaker, &Error{Code: 500})
+			require.Error(t, err)
+		}
+
+		_, err := call(breaker, nil)
+		require.NoError(t, err)
+		assert.Equal(t, CircuitBreakerClosed, breaker.State(), "success should reset failures")
+
+		for i := 0; i < 3; i++ {
+			_, err := call(breaker, &HTTPError{StatusCode: 502})
+			require.Error(t, err)
+		}
+
+		assert.Equal(t, CircuitBreakerOpen, breaker.State())
+
+		now = now.Add(10 * time.Second)
+
+		called, err := call(breaker, nil)
+		assert.False(t, called, "should fail fast")
+		require.ErrorIs(t, err, ErrCircuitOpen)
+
+		var openErr *CircuitOpenError
+		require.ErrorAs(t, err, &openErr)
+		assert.Equal(t, 50*time.Second, openErr.RetryAfter)
+
+		assert.Equal(t, []transition{{CircuitBreakerClosed, CircuitBreakerOpen}}, transitions)
+	})
+
+	t.Run("NotFailure", func(t *testing.T) {
+		now := time.Now()
+		var transitions []transition
+
+		breaker := newBreaker(&now, &transitions)
+
+		for i := 0; i < 5; i++ {
+			_, err := call(breaker, &Error{Code: 403, Message: "Forbidden: bot was blocked by the user"})
+			require.Error(t, err)
+		}
+
+		assert.Equal(t, CircuitBreakerClosed, breaker.State())
+	})
+
+	t.Run("HalfOpen", func(t *testing.T) {
+		now := time.Now()
+		var transitions []transition
+
+		breaker := newBreaker(&now, &transitions, WithCircuitBreakerThreshold(1))
+
+		_, err := call(breaker, &Error{Code: 500})
+		require.Error(t, err)
+
+		now = now.Add(time.Minute)
+		assert.Equal(t, CircuitBreakerHalfOpen, breaker.State())
+
+		// failed probe opens circuit again
+		called, err := call(breaker, &Error{Code: 500})
+		assert.True(t, called)
+		require.Error(t, err)
+		assert.Equal(t, CircuitBreakerOpen, breaker.State())
+
+		now = now.Add(time.Minute)
+
+		// successful probe closes circuit
+		called, err = call(breaker, nil)
+		assert.True(t, called)
+		require.NoError(t, err)
+		assert.Equal(t, CircuitBreakerClosed, breaker.State())
+
+		assert.Equal(t, []transition{
+			{CircuitBreakerClosed, CircuitBreakerOpen},
+			{CircuitBreakerOpen, CircuitBreakerHalfOpen},
+			{CircuitBreakerHalfOpen, CircuitBreakerOpen},
+			{CircuitBreakerOpen, CircuitBreakerHalfOpen},
+			{CircuitBreakerHalfOpen, CircuitBreakerClosed},
+		}, transitions)
+	})
+
+	t.Run("HalfOpenLimit", func(t *testing.T) {
+		now := time.Now()
+		var transitions []transition
+
+		breaker := newBreaker(&now, &transitions, WithCircuitBreakerThreshold(1))
+
+		_, err := call(breaker, &Error{Code: 500})
+		require.Error(t, err)
+
+		now = now.Add(time.Minute)
+
+		probing := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan error)
+
+		go func() {
+			done <- breaker.Intercept(context.Background(), NewRequest("getMe"), nil,
+				func(ctx context.Context, req *Request, dst any) error {
+					close(probing)
+					<-release
+					return nil
+				},
+			)
+		}()
+
+		<-probing
+
+		called, err := call(breaker, nil)
+		assert.False(t, called, "should not pass second probe")
+
+		var openErr *CircuitOpenError
+		require.ErrorAs(t, err, &openErr)
+		assert.Zero(t, openErr.RetryAfter)
+
+		close(release)
+		require.NoError(t, <-done)
+		assert.Equal(t, CircuitBreakerClosed, breaker.State())
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		now := time.Now()
+		var transitions []transition
+
+		breaker := newBreaker(&now, &transitions, WithCircuitBreakerThreshold(1))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := breaker.Intercept(ctx, NewRequest("getMe"), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				return ctx.Err()
+			},
+		)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, CircuitBreakerClosed, breaker.State(), "canceled request is not failure")
+	})
+
+	t.Run("Network", func(t *testing.T) {
+		now := time.Now()
+		var transitions []transition
+
+		breaker := newBreaker(&now, &transitions, WithCircuitBreakerThreshold(2))
+
+		_, err := call(breaker, context.DeadlineExceeded)
+		require.Error(t, err)
+
+		_, err = call(breaker, fmt.Errorf("read response: %w", io.ErrUnexpectedEOF))
+		require.Error(t, err)
+
+		assert.Equal(t, CircuitBreakerOpen, breaker.State())
+	})
+
+	t.Run("NotNetwork", func(t *testing.T) {
+		now := time.Now()
+		var transitions []transition
+
+		breaker := newBreaker(&now, &transitions, WithCircuitBreakerThreshold(1))
+
+		for _, failure := range []error{
+			&url.Error{Op: "Post", URL: "ftp://example.com", Err: errors.New(`unsupported protocol scheme "ftp"`)},
+			&url.Error{Op: "Post", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}},
+			&url.Error{Op: "Post", Err: &uploadError{err: io.ErrUnexpectedEOF}},
+		} {
+			_, err := call(breaker, failure)
+			require.Error(t, err)
+		}
+
+		assert.Equal(t, CircuitBreakerClosed, breaker.State(), "client side errors are not outage")
+		assert.Empty(t, transitions)
+	})
+}
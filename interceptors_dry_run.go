@@ -0,0 +1,148 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type interceptorDryRunOpts struct {
+	mutating func(method string) bool
+	now      func() time.Time
+}
+
+// InterceptorDryRunOption is an option for NewInterceptorDryRun.
+type InterceptorDryRunOption func(*interceptorDryRunOpts)
+
+// WithInterceptorDryRunMutating sets the function that decides which methods are short-circuited.
+// By default all methods except get* are short-circuited.
+func WithInterceptorDryRunMutating(mutating func(method string) bool) InterceptorDryRunOption {
+	return func(o *interceptorDryRunOpts) {
+		o.mutating = mutating
+	}
+}
+
+// WithInterceptorDryRunNow sets the time.Now function.
+func WithInterceptorDryRunNow(now func() time.Time) InterceptorDryRunOption {
+	return func(o *interceptorDryRunOpts) {
+		o.now = now
+	}
+}
+
+// NewInterceptorDryRun returns a new interceptor that doesn't call Bot API for mutating methods,
+// e.g. to run production code in staging environment without sending anything to real users.
+//
+// Each short-circuited request is passed to sink as [RequestRecord] and synthetic result is returned:
+//   - [Message] with chat id and date, message id is incrementing for send and forward methods
+//     and taken from message_id argument for others (e.g. edit methods);
+//   - slice of [Message] for each item of media group;
+//   - [MessageID] or slice of [MessageID] for copy and forward methods;
+//   - true for methods returning bool.
+//
+// Other results are left empty. Methods starting with "get" are passed to Bot API.
+// Keep in mind, short-circuited webhook methods (setWebhook, deleteWebhook) don't change the bot state.
+func NewInterceptorDryRun(sink func(ctx context.Context, record *RequestRecord), opts ...InterceptorDryRunOption) Interceptor {
+	options := &interceptorDryRunOpts{
+		mutating: func(method string) bool {
+			return !strings.HasPrefix(method, "get")
+		},
+		now: time.Now,
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	var messageID atomic.Int64
+
+	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+		if !options.mutating(req.Method) {
+			return invoker(ctx, req, dst)
+		}
+
+		record, err := req.Record()
+		if err != nil {
+			return fmt.Errorf("record request: %w", err)
+		}
+
+		sink(ctx, record)
+
+		nextMessageID := func() int {
+			return int(messageID.Add(1))
+		}
+
+		switch result := dst.(type) {
+		case *Message:
+			*result = dryRunMessage(req, dryRunMessageID(req, nextMessageID), options.now())
+		case *[]Message:
+			n := dryRunCount(req, "media")
+
+			*result = make([]Message, n)
+			for i := range *result {
+				(*result)[i] = dryRunMessage(req, nextMessageID(), options.now())
+			}
+		case *MessageID:
+			*result = MessageID{MessageID: nextMessageID()}
+		case *[]MessageID:
+			n := dryRunCount(req, "message_ids")
+
+			*result = make([]MessageID, n)
+			for i := range *result {
+				(*result)[i] = MessageID{MessageID: nextMessageID()}
+			}
+		case *bool:
+			*result = true
+		}
+
+		return nil
+	}
+}
+
+// dryRunMessageID returns id of message returned by request:
+// new one for methods creating messages, id of message from arguments for others (e.g. edit methods).
+func dryRunMessageID(req *Request, next func() int) int {
+	if strings.HasPrefix(req.Method, "send") || strings.HasPrefix(req.Method, "forward") {
+		return next()
+	}
+
+	if messageID, ok := req.GetArg("message_id"); ok {
+		id, _ := strconv.Atoi(messageID)
+		return id
+	}
+
+	return 0
+}
+
+// dryRunMessage returns synthetic message sent by request.
+func dryRunMessage(req *Request, id int, now time.Time) Message {
+	msg := Message{
+		ID:   id,
+		Date: UnixTime(now.Unix()),
+	}
+
+	if chatID, ok := req.GetArg("chat_id"); ok {
+		// usernames of channels are left as zero id
+		id, _ := strconv.ParseInt(chatID, 10, 64)
+		msg.Chat.ID = ChatID(id)
+	}
+
+	return msg
+}
+
+// dryRunCount returns the length of slice passed as JSON argument, 1 if it's not a slice.
+func dryRunCount(req *Request, name string) int {
+	v, ok := req.GetJSON(name)
+	if !ok {
+		return 1
+	}
+
+	if value := reflect.ValueOf(v); value.Kind() == reflect.Slice {
+		return value.Len()
+	}
+
+	return 1
+}
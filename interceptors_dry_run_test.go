@@ -0,0 +1,151 @@
+package tg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInterceptorDryRun(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	newInterceptor := func(records *[]*RequestRecord) Interceptor {
+		return NewInterceptorDryRun(
+			func(ctx context.Context, record *RequestRecord) {
+				*records = append(*records, record)
+			},
+			WithInterceptorDryRunNow(func() time.Time { return now }),
+		)
+	}
+
+	notCalled := func(t *testing.T) InterceptorInvoker {
+		return func(ctx context.Context, req *Request, dst any) error {
+			t.Errorf("unexpected call of %s", req.Method)
+			return nil
+		}
+	}
+
+	t.Run("Message", func(t *testing.T) {
+		var records []*RequestRecord
+
+		interceptor := newInterceptor(&records)
+
+		for _, id := range []int{1, 2} {
+			var msg Message
+
+			err := interceptor(context.Background(), NewRequest("sendMessage").ChatID("chat_id", 123).String("text", "hello"), &msg, notCalled(t))
+			require.NoError(t, err)
+
+			assert.Equal(t, Message{
+				ID:   id,
+				Date: UnixTime(now.Unix()),
+				Chat: Chat{ID: 123},
+			}, msg)
+		}
+
+		require.Len(t, records, 2)
+		assert.Equal(t, &RequestRecord{
+			Method: "sendMessage",
+			Args:   map[string]string{"chat_id": "123", "text": "hello"},
+			Files:  map[string]RequestRecordFile{},
+		}, records[0])
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		var records []*RequestRecord
+
+		interceptor := newInterceptor(&records)
+
+		var msg Message
+
+		err := interceptor(context.Background(), NewRequest("sendMessage").ChatID("chat_id", 123), &msg, notCalled(t))
+		require.NoError(t, err)
+		assert.Equal(t, 1, msg.ID)
+
+		err = interceptor(context.Background(),
+			NewRequest("editMessageText").ChatID("chat_id", 123).Int("message_id", 42).String("text", "edited"),
+			&msg, notCalled(t),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, Message{
+			ID:   42,
+			Date: UnixTime(now.Unix()),
+			Chat: Chat{ID: 123},
+		}, msg, "edited message id should be echoed")
+
+		err = interceptor(context.Background(), NewRequest("sendMessage").ChatID("chat_id", 123), &msg, notCalled(t))
+		require.NoError(t, err)
+		assert.Equal(t, 2, msg.ID, "edit should not take new id")
+	})
+
+	t.Run("MediaGroup", func(t *testing.T) {
+		var records []*RequestRecord
+
+		interceptor := newInterceptor(&records)
+
+		req := NewRequest("sendMediaGroup").
+			ChatID("chat_id", 123).
+			InputMediaSlice("media", []InputMedia{
+				{Photo: &InputMediaPhoto{Media: FileArg{Upload: NewInputFileBytes("photo.jpg", []byte("photo"))}}},
+				{Photo: &InputMediaPhoto{Media: FileArg{FileID: "file"}}},
+			})
+
+		var msgs []Message
+
+		err := interceptor(context.Background(), req, &msgs, notCalled(t))
+		require.NoError(t, err)
+
+		require.Len(t, msgs, 2)
+		assert.Equal(t, 1, msgs[0].ID)
+		assert.Equal(t, 2, msgs[1].ID)
+
+		require.Len(t, records, 1)
+		assert.Equal(t, map[string]RequestRecordFile{
+			"attachment_0": {Name: "photo.jpg", Size: 5},
+		}, records[0].Files)
+	})
+
+	t.Run("CopyMessages", func(t *testing.T) {
+		var records []*RequestRecord
+
+		interceptor := newInterceptor(&records)
+
+		var ids []MessageID
+
+		err := interceptor(context.Background(), NewRequest("copyMessages").JSON("message_ids", []int{10, 11, 12}), &ids, notCalled(t))
+		require.NoError(t, err)
+		assert.Equal(t, []MessageID{{1}, {2}, {3}}, ids)
+	})
+
+	t.Run("Bool", func(t *testing.T) {
+		var records []*RequestRecord
+
+		interceptor := newInterceptor(&records)
+
+		var result bool
+
+		err := interceptor(context.Background(), NewRequest("banChatMember"), &result, notCalled(t))
+		require.NoError(t, err)
+		assert.True(t, result)
+	})
+
+	t.Run("NotMutating", func(t *testing.T) {
+		var records []*RequestRecord
+
+		interceptor := newInterceptor(&records)
+
+		var calls int
+
+		err := interceptor(context.Background(), NewRequest("getMe"), nil, func(ctx context.Context, req *Request, dst any) error {
+			calls++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Empty(t, records)
+	})
+}
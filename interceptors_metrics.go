@@ -0,0 +1,117 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RequestRecorder records metrics of Bot API requests.
+// Implementations must be safe for concurrent use.
+type RequestRecorder interface {
+	// RecordRequest is called after each request with its method, duration and error (nil if request succeeded).
+	RecordRequest(ctx context.Context, method string, duration time.Duration, err error)
+}
+
+// RequestResult returns short label of request result for metrics:
+// "ok" if err is nil, error code for [Error] and HTTP status for [HTTPError],
+// "canceled" for context errors and "error" for others (e.g. network errors).
+func RequestResult(err error) string {
+	if err == nil {
+		return "ok"
+	}
+
+	var tgErr *Error
+	if errors.As(err, &tgErr) {
+		return strconv.Itoa(tgErr.Code)
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return strconv.Itoa(httpErr.StatusCode)
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "canceled"
+	}
+
+	return "error"
+}
+
+// RequestRecorderExpvar is a [RequestRecorder] which publishes metrics via [expvar].
+//
+// Metrics are grouped by method:
+//
+//	{
+//	  "sendMessage": {
+//	    "count": 10,
+//	    "duration_seconds": 1.5,
+//	    "results": {"ok": 9, "403": 1}
+//	  }
+//	}
+//
+// Where count is number of requests, duration_seconds is total duration of requests
+// and results is number of requests by result, see [RequestResult].
+type RequestRecorderExpvar struct {
+	lock    sync.Mutex
+	methods *expvar.Map
+}
+
+var _ RequestRecorder = (*RequestRecorderExpvar)(nil)
+
+// NewRequestRecorderExpvar creates a new recorder and publishes its metrics as expvar with given name.
+// Like [expvar.Publish], it panics if the name is already registered.
+func NewRequestRecorderExpvar(name string) *RequestRecorderExpvar {
+	return &RequestRecorderExpvar{
+		methods: expvar.NewMap(name),
+	}
+}
+
+func (recorder *RequestRecorderExpvar) RecordRequest(ctx context.Context, method string, duration time.Duration, err error) {
+	metrics := recorder.method(method)
+
+	metrics.Add("count", 1)
+	metrics.AddFloat("duration_seconds", duration.Seconds())
+	metrics.Get("results").(*expvar.Map).Add(RequestResult(err), 1)
+}
+
+// method returns metrics of method, creating them if needed.
+func (recorder *RequestRecorderExpvar) method(method string) *expvar.Map {
+	if metrics, ok := recorder.methods.Get(method).(*expvar.Map); ok {
+		return metrics
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	if metrics, ok := recorder.methods.Get(method).(*expvar.Map); ok {
+		return metrics
+	}
+
+	metrics := new(expvar.Map).Init()
+	metrics.Set("count", new(expvar.Int))
+	metrics.Set("duration_seconds", new(expvar.Float))
+	metrics.Set("results", new(expvar.Map).Init())
+
+	recorder.methods.Set(method, metrics)
+
+	return metrics
+}
+
+// NewInterceptorMetrics returns a new interceptor that records metrics of requests to recorder.
+// Register it before retrying interceptors to record the total duration of request including retries,
+// or after them to record each try.
+func NewInterceptorMetrics(recorder RequestRecorder) Interceptor {
+	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+		start := time.Now()
+
+		err := invoker(ctx, req, dst)
+
+		recorder.RecordRequest(ctx, req.Method, time.Since(start), err)
+
+		return err
+	}
+}
@@ -0,0 +1,61 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestResult(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want string
+	}{
+		{nil, "ok"},
+		{&Error{Code: 403}, "403"},
+		{fmt.Errorf("wrapped: %w", &Error{Code: 400}), "400"},
+		{&HTTPError{StatusCode: 502}, "502"},
+		{context.DeadlineExceeded, "canceled"},
+		{errors.New("connection refused"), "error"},
+	} {
+		assert.Equal(t, test.want, RequestResult(test.err))
+	}
+}
+
+func TestNewInterceptorMetrics(t *testing.T) {
+	name := fmt.Sprintf("tg_test_requests_%d", time.Now().UnixNano())
+
+	recorder := NewRequestRecorderExpvar(name)
+
+	interceptor := NewInterceptorMetrics(recorder)
+
+	for _, err := range []error{nil, nil, &Error{Code: 403}} {
+		result := interceptor(context.Background(), NewRequest("sendMessage"), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				time.Sleep(time.Millisecond)
+				return err
+			},
+		)
+		assert.Equal(t, err, result)
+	}
+
+	var metrics map[string]struct {
+		Count           int            `json:"count"`
+		DurationSeconds float64        `json:"duration_seconds"`
+		Results         map[string]int `json:"results"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &metrics))
+
+	require.Contains(t, metrics, "sendMessage")
+	assert.Equal(t, 3, metrics["sendMessage"].Count)
+	assert.Equal(t, map[string]int{"ok": 2, "403": 1}, metrics["sendMessage"].Results)
+	assert.GreaterOrEqual(t, metrics["sendMessage"].DurationSeconds, 0.003)
+}
@@ -0,0 +1,304 @@
+package tg
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimit defines how many requests allowed per period.
+type rateLimit struct {
+	count  int
+	period time.Duration
+}
+
+// interval returns minimal interval between two requests.
+func (limit rateLimit) interval() time.Duration {
+	if limit.count <= 0 || limit.period <= 0 {
+		return 0
+	}
+
+	return limit.period / time.Duration(limit.count)
+}
+
+// rateLimitSweepSize is the number of tracked chats after which idle chats are removed.
+const rateLimitSweepSize = 1024
+
+// rateLimitSchedule is a sorted list of times reserved for requests.
+type rateLimitSchedule []time.Time
+
+// prune removes slots which can't affect reservations after now.
+func (schedule rateLimitSchedule) prune(now time.Time, interval time.Duration) rateLimitSchedule {
+	i := 0
+	for i < len(schedule) && !schedule[i].Add(interval).After(now) {
+		i++
+	}
+
+	return schedule[i:]
+}
+
+// next returns the earliest time not before at, which is at least interval away from all reserved slots.
+// Free gaps between reserved slots are reused.
+func (schedule rateLimitSchedule) next(at time.Time, interval time.Duration) time.Time {
+	for _, slot := range schedule {
+		if !slot.Add(interval).After(at) {
+			continue
+		}
+
+		if !at.Add(interval).After(slot) {
+			break
+		}
+
+		at = slot.Add(interval)
+	}
+
+	return at
+}
+
+// insert adds slot to the schedule keeping it sorted.
+func (schedule rateLimitSchedule) insert(slot time.Time) rateLimitSchedule {
+	i := sort.Search(len(schedule), func(i int) bool {
+		return schedule[i].After(slot)
+	})
+
+	return slices.Insert(schedule, i, slot)
+}
+
+// remove removes slot from the schedule if it exists.
+func (schedule rateLimitSchedule) remove(slot time.Time) rateLimitSchedule {
+	i := sort.Search(len(schedule), func(i int) bool {
+		return !schedule[i].Before(slot)
+	})
+
+	if i < len(schedule) && schedule[i].Equal(slot) {
+		return slices.Delete(schedule, i, i+1)
+	}
+
+	return schedule
+}
+
+// rateLimitChat is a schedule of single chat.
+type rateLimitChat struct {
+	slots    rateLimitSchedule
+	interval time.Duration
+}
+
+// rateLimiter schedules requests using virtual time slots.
+// Each chat has own slot sequence, which guarantees FIFO order of requests inside chat.
+// Global slots are shared by all chats, but requests queued to one chat don't delay requests to others.
+type rateLimiter struct {
+	lock sync.Mutex
+
+	global rateLimitSchedule
+	chats  map[string]*rateLimitChat
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		chats: make(map[string]*rateLimitChat),
+	}
+}
+
+// reserve reserves the slot for request to chat and returns time when request can be sent.
+func (limiter *rateLimiter) reserve(now time.Time, chat string, chatLimit, globalLimit rateLimit) time.Time {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	at := now
+
+	var state *rateLimitChat
+
+	if chat != "" {
+		state = limiter.chats[chat]
+		if state == nil {
+			if len(limiter.chats) >= rateLimitSweepSize {
+				limiter.sweep(now)
+			}
+
+			state = &rateLimitChat{}
+			limiter.chats[chat] = state
+		}
+
+		state.interval = chatLimit.interval()
+		state.slots = state.slots.prune(now, state.interval)
+
+		if n := len(state.slots); n > 0 {
+			if next := state.slots[n-1].Add(state.interval); next.After(at) {
+				at = next
+			}
+		}
+	}
+
+	interval := globalLimit.interval()
+
+	limiter.global = limiter.global.prune(now, interval)
+	at = limiter.global.next(at, interval)
+	limiter.global = limiter.global.insert(at)
+
+	if state != nil {
+		state.slots = append(state.slots, at)
+	}
+
+	return at
+}
+
+// release gives back the slot reserved for request to chat, e.g. when request is canceled.
+func (limiter *rateLimiter) release(chat string, at time.Time) {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	limiter.global = limiter.global.remove(at)
+
+	if state := limiter.chats[chat]; state != nil {
+		state.slots = state.slots.remove(at)
+	}
+}
+
+// sweep removes chats which slots are already in the past.
+func (limiter *rateLimiter) sweep(now time.Time) {
+	for chat, state := range limiter.chats {
+		if len(state.slots.prune(now, state.interval)) == 0 {
+			delete(limiter.chats, chat)
+		}
+	}
+}
+
+type interceptorRateLimitOpts struct {
+	global  rateLimit
+	private rateLimit
+	group   rateLimit
+
+	filter    func(req *Request) bool
+	now       func() time.Time
+	timeAfter func(time.Duration) <-chan time.Time
+}
+
+// InterceptorRateLimitOption is an option for NewInterceptorRateLimit.
+type InterceptorRateLimitOption func(*interceptorRateLimitOpts)
+
+// WithInterceptorRateLimitGlobal sets the limit of requests for all chats.
+func WithInterceptorRateLimitGlobal(count int, period time.Duration) InterceptorRateLimitOption {
+	return func(o *interceptorRateLimitOpts) {
+		o.global = rateLimit{count: count, period: period}
+	}
+}
+
+// WithInterceptorRateLimitPrivate sets the limit of requests for single private chat.
+func WithInterceptorRateLimitPrivate(count int, period time.Duration) InterceptorRateLimitOption {
+	return func(o *interceptorRateLimitOpts) {
+		o.private = rateLimit{count: count, period: period}
+	}
+}
+
+// WithInterceptorRateLimitGroup sets the limit of requests for single group, supergroup or channel.
+func WithInterceptorRateLimitGroup(count int, period time.Duration) InterceptorRateLimitOption {
+	return func(o *interceptorRateLimitOpts) {
+		o.group = rateLimit{count: count, period: period}
+	}
+}
+
+// WithInterceptorRateLimitFilter sets the function that decides which requests should be limited.
+// By default only methods that send messages are limited, see [IsSendMethod].
+func WithInterceptorRateLimitFilter(filter func(req *Request) bool) InterceptorRateLimitOption {
+	return func(o *interceptorRateLimitOpts) {
+		o.filter = filter
+	}
+}
+
+// WithInterceptorRateLimitNow sets the time.Now function.
+func WithInterceptorRateLimitNow(now func() time.Time) InterceptorRateLimitOption {
+	return func(o *interceptorRateLimitOpts) {
+		o.now = now
+	}
+}
+
+// WithInterceptorRateLimitTimeAfter sets the time.After function.
+func WithInterceptorRateLimitTimeAfter(timeAfter func(time.Duration) <-chan time.Time) InterceptorRateLimitOption {
+	return func(o *interceptorRateLimitOpts) {
+		o.timeAfter = timeAfter
+	}
+}
+
+// IsSendMethod reports whether the method sends new messages to chat.
+// Such methods are subject of Telegram broadcasting limits.
+func IsSendMethod(method string) bool {
+	return strings.HasPrefix(method, "send") ||
+		strings.HasPrefix(method, "forwardMessage") ||
+		strings.HasPrefix(method, "copyMessage")
+}
+
+// isPrivateChatID reports whether the chat_id argument points to private chat.
+// Groups, supergroups and channels have negative identifiers or usernames.
+func isPrivateChatID(chatID string) bool {
+	id, err := strconv.ParseInt(chatID, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return id > 0
+}
+
+// NewInterceptorRateLimit returns a new interceptor that throttles outgoing requests
+// according to Telegram broadcasting limits, see https://core.telegram.org/bots/faq#my-bot-is-hitting-limits-how-do-i-avoid-this
+//
+// Requests are queued per destination (chat_id argument of the request)
+// and sent with even intervals, so flood errors are avoided proactively.
+// Requests to the same chat are sent in the order they were made,
+// waiting requests to one chat don't delay requests to other chats.
+//
+// Default limits are 30 requests per second for all chats,
+// 1 request per second for a private chat and 20 requests per minute for a group.
+// Only methods that send messages are limited, other methods (getX, answerCallbackQuery, etc.) bypass the interceptor.
+// If the context is canceled while request is waiting, the context error is returned
+// and the reserved slot is released for other requests.
+func NewInterceptorRateLimit(opts ...InterceptorRateLimitOption) Interceptor {
+	options := &interceptorRateLimitOpts{
+		global:  rateLimit{count: 30, period: time.Second},
+		private: rateLimit{count: 1, period: time.Second},
+		group:   rateLimit{count: 20, period: time.Minute},
+
+		filter: func(req *Request) bool {
+			return IsSendMethod(req.Method)
+		},
+		now:       time.Now,
+		timeAfter: time.After,
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	limiter := newRateLimiter()
+
+	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+		if !options.filter(req) {
+			return invoker(ctx, req, dst)
+		}
+
+		chatID, _ := req.GetArg("chat_id")
+
+		chatLimit := options.group
+		if isPrivateChatID(chatID) {
+			chatLimit = options.private
+		}
+
+		now := options.now()
+
+		at := limiter.reserve(now, chatID, chatLimit, options.global)
+
+		if delay := at.Sub(now); delay > 0 {
+			select {
+			case <-options.timeAfter(delay):
+			case <-ctx.Done():
+				limiter.release(chatID, at)
+				return ctx.Err()
+			}
+		}
+
+		return invoker(ctx, req, dst)
+	}
+}
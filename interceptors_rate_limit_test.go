@@ -0,0 +1,177 @@
+package tg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInterceptorRateLimit(t *testing.T) {
+	newInterceptor := func(delays *[]time.Duration, opts ...InterceptorRateLimitOption) Interceptor {
+		now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		return NewInterceptorRateLimit(append([]InterceptorRateLimitOption{
+			WithInterceptorRateLimitNow(func() time.Time { return now }),
+			WithInterceptorRateLimitTimeAfter(func(d time.Duration) <-chan time.Time {
+				*delays = append(*delays, d)
+				result := make(chan time.Time, 1)
+				result <- now.Add(d)
+				return result
+			}),
+		}, opts...)...)
+	}
+
+	invoker := InterceptorInvoker(func(ctx context.Context, req *Request, dst any) error {
+		return nil
+	})
+
+	t.Run("Bypass", func(t *testing.T) {
+		var delays []time.Duration
+
+		interceptor := newInterceptor(&delays)
+
+		for i := 0; i < 3; i++ {
+			err := interceptor(context.Background(), NewRequest("getMe"), nil, invoker)
+			require.NoError(t, err)
+		}
+
+		assert.Empty(t, delays, "should not delay non-sending methods")
+	})
+
+	t.Run("Private", func(t *testing.T) {
+		var delays []time.Duration
+
+		interceptor := newInterceptor(&delays)
+
+		for i := 0; i < 3; i++ {
+			err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "1"), nil, invoker)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, delays)
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		var delays []time.Duration
+
+		interceptor := newInterceptor(&delays)
+
+		for i := 0; i < 2; i++ {
+			err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "-100123"), nil, invoker)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []time.Duration{3 * time.Second}, delays)
+	})
+
+	t.Run("Global", func(t *testing.T) {
+		var delays []time.Duration
+
+		interceptor := newInterceptor(&delays,
+			WithInterceptorRateLimitGlobal(2, time.Second),
+		)
+
+		for _, chatID := range []string{"1", "2", "3"} {
+			err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", chatID), nil, invoker)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, delays)
+	})
+
+	t.Run("Mixed", func(t *testing.T) {
+		var delays []time.Duration
+
+		interceptor := newInterceptor(&delays)
+
+		for _, chatID := range []string{"-100123", "-100123", "1"} {
+			err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", chatID), nil, invoker)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []time.Duration{3 * time.Second, time.Second / 30}, delays,
+			"queued group message should not delay private chat",
+		)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		var delays []time.Duration
+
+		interceptor := newInterceptor(&delays,
+			WithInterceptorRateLimitFilter(func(req *Request) bool { return true }),
+		)
+
+		for i := 0; i < 2; i++ {
+			err := interceptor(context.Background(), NewRequest("editMessageText").String("chat_id", "1"), nil, invoker)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []time.Duration{time.Second}, delays)
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		var calls int
+
+		interceptor := NewInterceptorRateLimit(
+			WithInterceptorRateLimitPrivate(1, time.Hour),
+		)
+
+		countingInvoker := InterceptorInvoker(func(ctx context.Context, req *Request, dst any) error {
+			calls++
+			return nil
+		})
+
+		err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "1"), nil, countingInvoker)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err = interceptor(ctx, NewRequest("sendMessage").String("chat_id", "1"), nil, countingInvoker)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 1, calls, "should call invoker once")
+	})
+
+	t.Run("ContextCanceledRelease", func(t *testing.T) {
+		var delays []time.Duration
+
+		now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		interceptor := NewInterceptorRateLimit(
+			WithInterceptorRateLimitPrivate(1, time.Hour),
+			WithInterceptorRateLimitNow(func() time.Time { return now }),
+			WithInterceptorRateLimitTimeAfter(func(d time.Duration) <-chan time.Time {
+				delays = append(delays, d)
+				return make(chan time.Time)
+			}),
+		)
+
+		err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "1"), nil, invoker)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		for i := 0; i < 3; i++ {
+			err = interceptor(ctx, NewRequest("sendMessage").String("chat_id", "1"), nil, invoker)
+			require.ErrorIs(t, err, context.Canceled)
+		}
+
+		assert.Equal(t, []time.Duration{time.Hour, time.Hour, time.Hour}, delays,
+			"canceled requests should release reserved slots",
+		)
+	})
+}
+
+func TestIsSendMethod(t *testing.T) {
+	for _, method := range []string{"sendMessage", "sendMediaGroup", "forwardMessage", "forwardMessages", "copyMessage", "copyMessages"} {
+		assert.True(t, IsSendMethod(method), method)
+	}
+
+	for _, method := range []string{"getMe", "answerCallbackQuery", "editMessageText"} {
+		assert.False(t, IsSendMethod(method), method)
+	}
+}
@@ -0,0 +1,336 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides whether the failed request should be retried.
+type RetryPolicy interface {
+	// Retry reports whether the request failed with err should be retried.
+	// If delay is positive, it's used instead of backoff delay, e.g. RetryAfter of flood error.
+	Retry(req *Request, err error) (retry bool, delay time.Duration)
+}
+
+// RetryPolicyFunc is a function implementing [RetryPolicy].
+type RetryPolicyFunc func(req *Request, err error) (bool, time.Duration)
+
+func (f RetryPolicyFunc) Retry(req *Request, err error) (bool, time.Duration) {
+	return f(req, err)
+}
+
+// RetryOnFlood retries requests failed with flood error (429) using RetryAfter as delay.
+// Errors with RetryAfter greater than maxRetryAfter are not retried.
+func RetryOnFlood(maxRetryAfter time.Duration) RetryPolicy {
+	return RetryPolicyFunc(func(req *Request, err error) (bool, time.Duration) {
+		var tgErr *Error
+		if !errors.As(err, &tgErr) || tgErr.Code != http.StatusTooManyRequests || tgErr.Parameters == nil {
+			return false, 0
+		}
+
+		retryAfter := tgErr.Parameters.RetryAfterDuration()
+		if retryAfter > maxRetryAfter {
+			return false, 0
+		}
+
+		return true, retryAfter
+	})
+}
+
+// RetryOnServerError retries requests failed with internal server error (500) of Bot API
+// or gateway failure (502, 503 and 504) of HTTP server in front of it, see [HTTPError].
+func RetryOnServerError() RetryPolicy {
+	return RetryPolicyFunc(func(req *Request, err error) (bool, time.Duration) {
+		return isServerError(err), 0
+	})
+}
+
+// RetryOnNetworkError retries requests failed with network errors, e.g. connection refused or reset.
+// Keep in mind, request could be processed by server before the connection is broken,
+// so retrying of non idempotent methods (like sendMessage) can lead to duplicates.
+func RetryOnNetworkError() RetryPolicy {
+	return RetryPolicyFunc(func(req *Request, err error) (bool, time.Duration) {
+		return isNetworkError(err), 0
+	})
+}
+
+// isNetworkError reports whether the error is caused by network failure, except timeouts.
+// Errors of request building (e.g. invalid URL), TLS handshake and reading of uploaded files are not network errors,
+// even though they are wrapped into [url.Error] by HTTP client too.
+func isNetworkError(err error) bool {
+	var uploadErr *uploadError
+	if errors.As(err, &uploadErr) || isTimeoutError(err) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// RetryOnTimeout retries requests failed with timeout of network operation or deadline of inner context
+// (e.g. [TimeoutError] of [NewInterceptorTimeout]). Requests are never retried if the context of call is done.
+func RetryOnTimeout() RetryPolicy {
+	return RetryPolicyFunc(func(req *Request, err error) (bool, time.Duration) {
+		return isTimeoutError(err), 0
+	})
+}
+
+// isTimeoutError reports whether the error is timeout of network operation or context deadline.
+func isTimeoutError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// RetryAny combines policies, the request is retried if any of them allows it.
+// Delay of the first matched policy is used.
+func RetryAny(policies ...RetryPolicy) RetryPolicy {
+	return RetryPolicyFunc(func(req *Request, err error) (bool, time.Duration) {
+		for _, policy := range policies {
+			if retry, delay := policy.Retry(req, err); retry {
+				return true, delay
+			}
+		}
+
+		return false, 0
+	})
+}
+
+// RetryBackoff returns the delay before retry.
+// Attempt is the number of failed tries, starting from 1. Prev is the previous delay, 0 for the first retry.
+type RetryBackoff func(attempt int, prev time.Duration) time.Duration
+
+// RetryBackoffConstant waits the same delay before each retry.
+func RetryBackoffConstant(delay time.Duration) RetryBackoff {
+	return func(attempt int, prev time.Duration) time.Duration {
+		return delay
+	}
+}
+
+// RetryBackoffExponential waits base * 2^(attempt-1) plus random jitter of the same size before retry.
+// If max is positive, the delay is limited by it.
+func RetryBackoffExponential(base, max time.Duration) RetryBackoff {
+	return func(attempt int, prev time.Duration) time.Duration {
+		delay := base << (attempt - 1)
+		if delay <= 0 || (max > 0 && delay > max) {
+			delay = max
+		}
+
+		if delay > 0 {
+			delay += time.Duration(rand.Int63n(int64(delay)))
+		}
+
+		if max > 0 && delay > max {
+			delay = max
+		}
+
+		return delay
+	}
+}
+
+// RetryBackoffDecorrelatedJitter waits random delay between base and 3 * prev before retry,
+// limited by max (if positive). It spreads retries of concurrent requests better than exponential backoff.
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+func RetryBackoffDecorrelatedJitter(base, max time.Duration) RetryBackoff {
+	return func(attempt int, prev time.Duration) time.Duration {
+		if prev < base {
+			prev = base
+		}
+
+		delay := base
+		if upper := prev * 3; upper > base {
+			delay += time.Duration(rand.Int63n(int64(upper - base)))
+		}
+
+		if max > 0 && delay > max {
+			delay = max
+		}
+
+		return delay
+	}
+}
+
+type interceptorRetryOpts struct {
+	tries   int
+	policy  RetryPolicy
+	backoff RetryBackoff
+	budget  time.Duration
+	onRetry func(ctx context.Context, req *Request, attempt int, err error, delay time.Duration)
+	methods map[string][]InterceptorRetryOption
+
+	now       func() time.Time
+	timeAfter func(time.Duration) <-chan time.Time
+}
+
+// InterceptorRetryOption is an option for NewInterceptorRetry.
+type InterceptorRetryOption func(*interceptorRetryOpts)
+
+// WithInterceptorRetryTries sets the max number of tries, including the first one.
+func WithInterceptorRetryTries(tries int) InterceptorRetryOption {
+	return func(o *interceptorRetryOpts) {
+		o.tries = tries
+	}
+}
+
+// WithInterceptorRetryPolicy sets the policy which decides whether request should be retried.
+// Use [RetryAny] to combine multiple policies.
+func WithInterceptorRetryPolicy(policy RetryPolicy) InterceptorRetryOption {
+	return func(o *interceptorRetryOpts) {
+		o.policy = policy
+	}
+}
+
+// WithInterceptorRetryBackoff sets the backoff strategy.
+func WithInterceptorRetryBackoff(backoff RetryBackoff) InterceptorRetryOption {
+	return func(o *interceptorRetryOpts) {
+		o.backoff = backoff
+	}
+}
+
+// WithInterceptorRetryBudget sets the total time budget of the call including all tries and delays.
+// The request is not retried if the delay exceeds the rest of budget.
+func WithInterceptorRetryBudget(budget time.Duration) InterceptorRetryOption {
+	return func(o *interceptorRetryOpts) {
+		o.budget = budget
+	}
+}
+
+// WithInterceptorRetryOnRetry sets the function called before each retry,
+// e.g. to log or count retries. Attempt is the number of failed tries.
+func WithInterceptorRetryOnRetry(onRetry func(ctx context.Context, req *Request, attempt int, err error, delay time.Duration)) InterceptorRetryOption {
+	return func(o *interceptorRetryOpts) {
+		o.onRetry = onRetry
+	}
+}
+
+// WithInterceptorRetryMethod overrides options for the method.
+// Options are applied on top of other options of the interceptor.
+//
+//	tg.NewInterceptorRetry(
+//	  tg.WithInterceptorRetryTries(5),
+//	  tg.WithInterceptorRetryMethod("sendMessage", tg.WithInterceptorRetryTries(2)),
+//	)
+func WithInterceptorRetryMethod(method string, opts ...InterceptorRetryOption) InterceptorRetryOption {
+	return func(o *interceptorRetryOpts) {
+		o.methods[method] = append(o.methods[method], opts...)
+	}
+}
+
+// WithInterceptorRetryNow sets the time.Now function.
+func WithInterceptorRetryNow(now func() time.Time) InterceptorRetryOption {
+	return func(o *interceptorRetryOpts) {
+		o.now = now
+	}
+}
+
+// WithInterceptorRetryTimeAfter sets the time.After function.
+func WithInterceptorRetryTimeAfter(timeAfter func(time.Duration) <-chan time.Time) InterceptorRetryOption {
+	return func(o *interceptorRetryOpts) {
+		o.timeAfter = timeAfter
+	}
+}
+
+// NewInterceptorRetry returns a new interceptor that retries failed requests according to policy.
+//
+// Default tries is 3, policy retries flood errors with RetryAfter up to 1 hour and server errors
+// (see [RetryOnFlood] and [RetryOnServerError]), backoff is exponential from 100ms up to 30s.
+// The interceptor doesn't wait after the last try and stops if the context is done.
+func NewInterceptorRetry(opts ...InterceptorRetryOption) Interceptor {
+	options := &interceptorRetryOpts{
+		tries:   3,
+		policy:  RetryAny(RetryOnFlood(time.Hour), RetryOnServerError()),
+		backoff: RetryBackoffExponential(100*time.Millisecond, 30*time.Second),
+		onRetry: func(ctx context.Context, req *Request, attempt int, err error, delay time.Duration) {},
+		methods: make(map[string][]InterceptorRetryOption),
+
+		now:       time.Now,
+		timeAfter: time.After,
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	methods := make(map[string]*interceptorRetryOpts, len(options.methods))
+
+	for method, methodOpts := range options.methods {
+		methodOptions := *options
+		methodOptions.methods = make(map[string][]InterceptorRetryOption)
+
+		for _, o := range methodOpts {
+			o(&methodOptions)
+		}
+
+		methods[method] = &methodOptions
+	}
+
+	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+		if methodOptions, ok := methods[req.Method]; ok {
+			return methodOptions.do(ctx, req, dst, invoker)
+		}
+
+		return options.do(ctx, req, dst, invoker)
+	}
+}
+
+func (options *interceptorRetryOpts) do(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+	start := options.now()
+
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		err := invoker(ctx, req, dst)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= options.tries || ctx.Err() != nil {
+			return err
+		}
+
+		retry, policyDelay := options.policy.Retry(req, err)
+		if !retry {
+			return err
+		}
+
+		if policyDelay > 0 {
+			delay = policyDelay
+		} else {
+			delay = options.backoff(attempt, delay)
+		}
+
+		if options.budget > 0 && options.now().Sub(start)+delay > options.budget {
+			return err
+		}
+
+		options.onRetry(ctx, req, attempt, err, delay)
+
+		select {
+		case <-options.timeAfter(delay):
+			countRetry(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
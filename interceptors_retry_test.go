@@ -0,0 +1,323 @@
+package tg
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInterceptorRetry(t *testing.T) {
+	timeAfter := func(delays *[]time.Duration) func(time.Duration) <-chan time.Time {
+		return func(d time.Duration) <-chan time.Time {
+			*delays = append(*delays, d)
+			result := make(chan time.Time, 1)
+			result <- time.Now()
+			return result
+		}
+	}
+
+	failing := func(calls *int, errs ...error) InterceptorInvoker {
+		return func(ctx context.Context, req *Request, dst any) error {
+			*calls++
+			if *calls <= len(errs) {
+				return errs[*calls-1]
+			}
+			return nil
+		}
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		var (
+			calls  int
+			delays []time.Duration
+		)
+
+		interceptor := NewInterceptorRetry(
+			WithInterceptorRetryTimeAfter(timeAfter(&delays)),
+		)
+
+		err := interceptor(context.Background(), NewRequest("getMe"), nil, failing(&calls,
+			&Error{Code: 429, Parameters: &ResponseParameters{RetryAfter: 5}},
+			&Error{Code: 500},
+		))
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		require.Len(t, delays, 2)
+		assert.Equal(t, 5*time.Second, delays[0], "should use RetryAfter")
+		assert.GreaterOrEqual(t, delays[1], 200*time.Millisecond)
+		assert.Less(t, delays[1], 400*time.Millisecond)
+	})
+
+	t.Run("NotRetryable", func(t *testing.T) {
+		var (
+			calls  int
+			delays []time.Duration
+		)
+
+		interceptor := NewInterceptorRetry(
+			WithInterceptorRetryTimeAfter(timeAfter(&delays)),
+		)
+
+		err := interceptor(context.Background(), NewRequest("getMe"), nil, failing(&calls,
+			&Error{Code: 400},
+		))
+		require.Error(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Empty(t, delays)
+	})
+
+	t.Run("Tries", func(t *testing.T) {
+		var (
+			calls  int
+			delays []time.Duration
+		)
+
+		interceptor := NewInterceptorRetry(
+			WithInterceptorRetryTries(2),
+			WithInterceptorRetryTimeAfter(timeAfter(&delays)),
+		)
+
+		err := interceptor(context.Background(), NewRequest("getMe"), nil, failing(&calls,
+			&Error{Code: 500}, &Error{Code: 500}, &Error{Code: 500},
+		))
+		require.Error(t, err)
+		assert.Equal(t, 2, calls)
+		assert.Len(t, delays, 1, "should not wait after the last try")
+	})
+
+	t.Run("CustomPolicy", func(t *testing.T) {
+		var (
+			calls  int
+			delays []time.Duration
+		)
+
+		errNetwork := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+		errCustom := errors.New("custom")
+
+		interceptor := NewInterceptorRetry(
+			WithInterceptorRetryTries(5),
+			WithInterceptorRetryPolicy(RetryAny(
+				RetryOnNetworkError(),
+				RetryOnTimeout(),
+				RetryPolicyFunc(func(req *Request, err error) (bool, time.Duration) {
+					return errors.Is(err, errCustom), time.Minute
+				}),
+			)),
+			WithInterceptorRetryBackoff(RetryBackoffConstant(time.Second)),
+			WithInterceptorRetryTimeAfter(timeAfter(&delays)),
+		)
+
+		err := interceptor(context.Background(), NewRequest("getMe"), nil, failing(&calls,
+			errNetwork, context.DeadlineExceeded, errCustom,
+		))
+		require.NoError(t, err)
+		assert.Equal(t, 4, calls)
+		assert.Equal(t, []time.Duration{time.Second, time.Second, time.Minute}, delays)
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		var (
+			calls  int
+			delays []time.Duration
+		)
+
+		now := time.Now()
+
+		interceptor := NewInterceptorRetry(
+			WithInterceptorRetryTries(10),
+			WithInterceptorRetryBudget(5*time.Second),
+			WithInterceptorRetryBackoff(RetryBackoffConstant(2*time.Second)),
+			WithInterceptorRetryNow(func() time.Time { return now }),
+			WithInterceptorRetryTimeAfter(func(d time.Duration) <-chan time.Time {
+				now = now.Add(d)
+				return timeAfter(&delays)(d)
+			}),
+		)
+
+		err := interceptor(context.Background(), NewRequest("getMe"), nil, failing(&calls,
+			&Error{Code: 500}, &Error{Code: 500}, &Error{Code: 500},
+		))
+		require.Error(t, err)
+		assert.Equal(t, 3, calls, "should stop when delay exceeds budget")
+		assert.Len(t, delays, 2)
+	})
+
+	t.Run("Method", func(t *testing.T) {
+		var (
+			calls  int
+			delays []time.Duration
+		)
+
+		interceptor := NewInterceptorRetry(
+			WithInterceptorRetryTries(5),
+			WithInterceptorRetryBackoff(RetryBackoffConstant(time.Second)),
+			WithInterceptorRetryMethod("sendMessage", WithInterceptorRetryTries(2)),
+			WithInterceptorRetryTimeAfter(timeAfter(&delays)),
+		)
+
+		err := interceptor(context.Background(), NewRequest("sendMessage"), nil, failing(&calls,
+			&Error{Code: 500}, &Error{Code: 500},
+		))
+		require.Error(t, err)
+		assert.Equal(t, 2, calls, "should use tries of method")
+		assert.Equal(t, []time.Duration{time.Second}, delays, "should inherit backoff")
+
+		calls = 0
+
+		err = interceptor(context.Background(), NewRequest("getMe"), nil, failing(&calls,
+			&Error{Code: 500}, &Error{Code: 500},
+		))
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("OnRetry", func(t *testing.T) {
+		var (
+			calls    int
+			attempts []int
+		)
+
+		interceptor := NewInterceptorRetry(
+			WithInterceptorRetryBackoff(RetryBackoffConstant(time.Second)),
+			WithInterceptorRetryOnRetry(func(ctx context.Context, req *Request, attempt int, err error, delay time.Duration) {
+				assert.Equal(t, "getMe", req.Method)
+				assert.Equal(t, time.Second, delay)
+				assert.Error(t, err)
+				attempts = append(attempts, attempt)
+			}),
+			WithInterceptorRetryTimeAfter(timeAfter(&[]time.Duration{})),
+		)
+
+		ctx, retries := contextWithRetryCounter(context.Background())
+
+		err := interceptor(ctx, NewRequest("getMe"), nil, failing(&calls,
+			&Error{Code: 500}, &Error{Code: 502},
+		))
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, attempts)
+		assert.EqualValues(t, 2, retries.Load())
+	})
+
+	t.Run("ContextDone", func(t *testing.T) {
+		var calls int
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		interceptor := NewInterceptorRetry(
+			WithInterceptorRetryTimeAfter(func(time.Duration) <-chan time.Time {
+				cancel()
+				return make(chan time.Time)
+			}),
+		)
+
+		err := interceptor(ctx, NewRequest("getMe"), nil, failing(&calls,
+			&Error{Code: 500}, &Error{Code: 500},
+		))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Run("Exponential", func(t *testing.T) {
+		backoff := RetryBackoffExponential(time.Second, 10*time.Second)
+
+		for attempt, min := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+			delay := backoff(attempt+1, 0)
+			assert.GreaterOrEqual(t, delay, min)
+			assert.Less(t, delay, 2*min)
+		}
+
+		assert.Equal(t, 10*time.Second, backoff(5, 0), "should be limited by max")
+		assert.Equal(t, 10*time.Second, backoff(100, 0), "should not overflow")
+	})
+
+	t.Run("DecorrelatedJitter", func(t *testing.T) {
+		backoff := RetryBackoffDecorrelatedJitter(time.Second, 10*time.Second)
+
+		var prev time.Duration
+		for attempt := 1; attempt <= 10; attempt++ {
+			delay := backoff(attempt, prev)
+			assert.GreaterOrEqual(t, delay, time.Second)
+			assert.LessOrEqual(t, delay, 10*time.Second)
+			if prev > 0 {
+				assert.Less(t, delay, 3*prev+1)
+			}
+			prev = delay
+		}
+	})
+}
+
+func TestIsNetworkError(t *testing.T) {
+	for _, test := range []struct {
+		Name string
+		Err  error
+		Want bool
+	}{
+		{"ConnectionRefused", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, true},
+		{"ConnectionReset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"UnexpectedEOF", &url.Error{Op: "Post", Err: io.ErrUnexpectedEOF}, true},
+		{"TemporaryDNS", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "server misbehaving", IsTemporary: true}}}, true},
+		{"NotFoundDNS", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}}, false},
+		{"Timeout", &url.Error{Op: "Post", Err: &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}}, false},
+		{"X509", &url.Error{Op: "Post", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}}, false},
+		{"BadScheme", &url.Error{Op: "Post", Err: errors.New(`unsupported protocol scheme "ftp"`)}, false},
+		{"Upload", &url.Error{Op: "Post", Err: &uploadError{err: io.ErrUnexpectedEOF}}, false},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Want, isNetworkError(test.Err))
+		})
+	}
+
+	t.Run("Client", func(t *testing.T) {
+		var calls int
+
+		client := New("1234:secret",
+			WithClientServerURL("ftp://example.com"),
+			WithClientInterceptors(
+				NewInterceptorRetry(WithInterceptorRetryPolicy(RetryOnNetworkError())),
+				func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+					calls++
+					return invoker(ctx, req, dst)
+				},
+			),
+		)
+
+		err := client.GetMe().DoVoid(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, 1, calls, "request with unsupported scheme should not be retried")
+	})
+
+	t.Run("UploadReadError", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		}))
+		defer ts.Close()
+
+		client := New("1234:secret", WithClientServerURL(ts.URL))
+
+		err := client.Do(context.Background(),
+			NewRequest("sendDocument").
+				InputFile("document", NewInputFile("test.txt", iotest.ErrReader(io.ErrUnexpectedEOF))),
+			nil,
+		)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.False(t, isNetworkError(err), "read error of uploaded file is not network error")
+	})
+}
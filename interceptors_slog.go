@@ -0,0 +1,178 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// retryCounterKey is the context key of request retry counter.
+type retryCounterKey struct{}
+
+// contextWithRetryCounter returns the context with new retry counter of request.
+func contextWithRetryCounter(ctx context.Context) (context.Context, *atomic.Int32) {
+	counter := &atomic.Int32{}
+	return context.WithValue(ctx, retryCounterKey{}, counter), counter
+}
+
+// countRetry increments the retry counter of request, if any.
+// Retrying interceptors should call it before each retry.
+func countRetry(ctx context.Context) {
+	if counter, ok := ctx.Value(retryCounterKey{}).(*atomic.Int32); ok {
+		counter.Add(1)
+	}
+}
+
+type interceptorSlogOpts struct {
+	level      slog.Level
+	errorLevel slog.Level
+
+	args   bool
+	redact map[string]struct{}
+}
+
+// InterceptorSlogOption is an option for NewInterceptorSlog.
+type InterceptorSlogOption func(*interceptorSlogOpts)
+
+// WithInterceptorSlogLevel sets the level of successful requests log records.
+// Default is slog.LevelDebug.
+func WithInterceptorSlogLevel(level slog.Level) InterceptorSlogOption {
+	return func(o *interceptorSlogOpts) {
+		o.level = level
+	}
+}
+
+// WithInterceptorSlogErrorLevel sets the level of failed requests log records.
+// Default is slog.LevelError.
+func WithInterceptorSlogErrorLevel(level slog.Level) InterceptorSlogOption {
+	return func(o *interceptorSlogOpts) {
+		o.errorLevel = level
+	}
+}
+
+// WithInterceptorSlogArgs enables logging of request arguments in "args" group.
+// Values of arguments with names from redact are replaced with placeholder, e.g. "text" or "phone_number".
+// Uploaded files are logged by file name.
+func WithInterceptorSlogArgs(redact ...string) InterceptorSlogOption {
+	return func(o *interceptorSlogOpts) {
+		o.args = true
+
+		for _, name := range redact {
+			o.redact[name] = struct{}{}
+		}
+	}
+}
+
+// NewInterceptorSlog returns a new interceptor that logs requests with [log/slog].
+//
+// Each record contains method, duration and chat_id (if present) attributes.
+// Failed requests have error and error_kind (see [ErrorKind]) attributes.
+// If request was retried by interceptors registered after this one (e.g. [NewInterceptorRetryFloodError]),
+// number of retries is logged as retries attribute. So register it before retrying interceptors.
+func NewInterceptorSlog(logger *slog.Logger, opts ...InterceptorSlogOption) Interceptor {
+	options := &interceptorSlogOpts{
+		level:      slog.LevelDebug,
+		errorLevel: slog.LevelError,
+		redact:     make(map[string]struct{}),
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+		ctx, retries := contextWithRetryCounter(ctx)
+
+		start := time.Now()
+
+		err := invoker(ctx, req, dst)
+
+		level := options.level
+		if err != nil {
+			level = options.errorLevel
+		}
+
+		if !logger.Enabled(ctx, level) {
+			return err
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.Duration("duration", time.Since(start)),
+		}
+
+		if chatID, ok := req.GetArg("chat_id"); ok {
+			attrs = append(attrs, slog.String("chat_id", chatID))
+		}
+
+		if n := retries.Load(); n > 0 {
+			attrs = append(attrs, slog.Int("retries", int(n)))
+		}
+
+		if err != nil {
+			attrs = append(attrs,
+				slog.String("error", err.Error()),
+				slog.String("error_kind", errorKindOf(err).String()),
+			)
+		}
+
+		if options.args {
+			attrs = append(attrs, slog.Attr{
+				Key:   "args",
+				Value: slog.GroupValue(requestSlogArgs(req, options.redact)...),
+			})
+		}
+
+		logger.LogAttrs(ctx, level, "tg request", attrs...)
+
+		return err
+	}
+}
+
+// errorKindOf returns the kind of Bot API error in chain of err.
+func errorKindOf(err error) ErrorKind {
+	var tgErr *Error
+	if errors.As(err, &tgErr) {
+		return tgErr.Kind()
+	}
+
+	return ErrorKindUnknown
+}
+
+// requestSlogArgs returns arguments of request as log attributes sorted by name.
+func requestSlogArgs(req *Request, redact map[string]struct{}) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(req.args)+len(req.json)+len(req.files))
+
+	value := func(name string, v slog.Value) slog.Value {
+		if _, ok := redact[name]; ok {
+			return slog.StringValue(redactedToken)
+		}
+
+		return v
+	}
+
+	for k, v := range req.args {
+		if _, ok := req.json[k]; ok {
+			continue
+		}
+
+		attrs = append(attrs, slog.Attr{Key: k, Value: value(k, slog.StringValue(v))})
+	}
+
+	for k, v := range req.json {
+		attrs = append(attrs, slog.Attr{Key: k, Value: value(k, slog.AnyValue(v))})
+	}
+
+	for k, v := range req.files {
+		attrs = append(attrs, slog.Attr{Key: k, Value: value(k, slog.StringValue(v.Name))})
+	}
+
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+
+	return attrs
+}
@@ -0,0 +1,150 @@
+package tg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInterceptorSlog(t *testing.T) {
+	newLogger := func(buf *bytes.Buffer) *slog.Logger {
+		return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == "duration") {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+	}
+
+	decode := func(t *testing.T, buf *bytes.Buffer) map[string]any {
+		t.Helper()
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		return record
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		interceptor := NewInterceptorSlog(newLogger(buf))
+
+		err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "1").String("text", "hello"), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{
+			"level":   "DEBUG",
+			"msg":     "tg request",
+			"method":  "sendMessage",
+			"chat_id": "1",
+		}, decode(t, buf))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		interceptor := NewInterceptorSlog(newLogger(buf), WithInterceptorSlogErrorLevel(slog.LevelWarn))
+
+		err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "1"), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				return &Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+			},
+		)
+		require.Error(t, err)
+
+		assert.Equal(t, map[string]any{
+			"level":      "WARN",
+			"msg":        "tg request",
+			"method":     "sendMessage",
+			"chat_id":    "1",
+			"error":      "403: Forbidden: bot was blocked by the user",
+			"error_kind": "bot_blocked",
+		}, decode(t, buf))
+	})
+
+	t.Run("Retries", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		calls := 0
+
+		retry := NewInterceptorRetryInternalServerError(
+			WithInterceptorRetryInternalServerErrorTimeAfter(func(time.Duration) <-chan time.Time {
+				result := make(chan time.Time, 1)
+				result <- time.Now()
+				return result
+			}),
+		)
+
+		interceptor := NewInterceptorSlog(newLogger(buf))
+
+		err := interceptor(context.Background(), NewRequest("getMe"), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				return retry(ctx, req, dst, func(ctx context.Context, req *Request, dst any) error {
+					calls++
+					if calls < 3 {
+						return &Error{Code: 500}
+					}
+					return nil
+				})
+			},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2.0, decode(t, buf)["retries"])
+	})
+
+	t.Run("Args", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		interceptor := NewInterceptorSlog(newLogger(buf), WithInterceptorSlogArgs("text"))
+
+		req := NewRequest("sendDocument").
+			String("chat_id", "1").
+			String("text", "secret").
+			JSON("reply_markup", map[string]any{"remove_keyboard": true}).
+			InputFile("document", NewInputFileBytes("test.txt", []byte("test")))
+
+		err := interceptor(context.Background(), req, nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{
+			"chat_id":      "1",
+			"text":         "<redacted>",
+			"reply_markup": map[string]any{"remove_keyboard": true},
+			"document":     "test.txt",
+		}, decode(t, buf)["args"])
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+		interceptor := NewInterceptorSlog(logger)
+
+		err := interceptor(context.Background(), NewRequest("getMe"), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				return nil
+			},
+		)
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+}
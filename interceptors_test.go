@@ -68,7 +68,7 @@ func TestNewInterceptorRetryFloodError(t *testing.T) {
 
 		require.Error(t, err, "should return error")
 		assert.Equal(t, 3, calls, "should call invoker 3 times")
-		assert.Equal(t, 3, timeAfterCalls, "should call timeAfter 3 times")
+		assert.Equal(t, 2, timeAfterCalls, "should not wait after the last try")
 	})
 
 	t.Run("MaxRetryAfter", func(t *testing.T) {
@@ -181,7 +181,28 @@ func TestNewInterceptorRetryInternalServerError(t *testing.T) {
 
 		require.Error(t, err, "should return error")
 		assert.Equal(t, 3, calls, "should call invoker 3 times")
-		assert.Equal(t, 3, timeAfterCalls, "should call timeAfter 3 times")
+		assert.Equal(t, 2, timeAfterCalls, "should not wait after the last try")
+	})
+
+	t.Run("RetryGateway", func(t *testing.T) {
+		var calls int
+
+		invoker := InterceptorInvoker(func(ctx context.Context, req *Request, dst any) error {
+			calls++
+			if calls == 1 {
+				return &HTTPError{StatusCode: 502}
+			}
+			return nil
+		})
+
+		interceptor := NewInterceptorRetryInternalServerError(
+			WithInterceptorRetryInternalServerErrorDelay(time.Millisecond),
+		)
+
+		err := interceptor(context.Background(), &Request{}, nil, invoker)
+
+		require.NoError(t, err, "should no return error")
+		assert.Equal(t, 2, calls, "should call invoker 2 times")
 	})
 
 	t.Run("Timeout", func(t *testing.T) {
@@ -270,3 +291,131 @@ func TestNewInterceptorDefaultParseMethod(t *testing.T) {
 		assert.Equal(t, 1, calls, "should call invoker once")
 	})
 }
+
+func TestNewInterceptorChatMigration(t *testing.T) {
+	t.Run("NoError", func(t *testing.T) {
+		var calls int
+
+		invoker := InterceptorInvoker(func(ctx context.Context, req *Request, dst any) error {
+			calls++
+			return nil
+		})
+
+		interceptor := NewInterceptorChatMigration()
+
+		err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "-123"), nil, invoker)
+
+		require.NoError(t, err, "should no return error")
+		assert.Equal(t, 1, calls, "should call invoker once")
+	})
+
+	t.Run("NoMigration", func(t *testing.T) {
+		var calls int
+
+		invoker := InterceptorInvoker(func(ctx context.Context, req *Request, dst any) error {
+			calls++
+			return &Error{Code: 400, Message: "Bad Request: chat not found"}
+		})
+
+		interceptor := NewInterceptorChatMigration()
+
+		err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "-123"), nil, invoker)
+
+		require.Error(t, err, "should return error")
+		assert.Equal(t, 1, calls, "should call invoker once")
+	})
+
+	t.Run("Migrate", func(t *testing.T) {
+		var chatIDs []string
+
+		invoker := InterceptorInvoker(func(ctx context.Context, req *Request, dst any) error {
+			chatID, _ := req.GetArg("chat_id")
+			chatIDs = append(chatIDs, chatID)
+
+			if chatID == "-123" {
+				return &Error{
+					Code:       400,
+					Message:    "Bad Request: group chat was upgraded to a supergroup chat",
+					Parameters: &ResponseParameters{MigrateToChatID: -100123},
+				}
+			}
+
+			return nil
+		})
+
+		var from, to ChatID
+
+		interceptor := NewInterceptorChatMigration(
+			WithInterceptorChatMigrationHook(func(ctx context.Context, f, t ChatID) error {
+				from, to = f, t
+				return nil
+			}),
+		)
+
+		err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "-123"), nil, invoker)
+
+		require.NoError(t, err, "should no return error")
+		assert.Equal(t, []string{"-123", "-100123"}, chatIDs, "should retry with new chat id")
+		assert.Equal(t, ChatID(-123), from)
+		assert.Equal(t, ChatID(-100123), to)
+	})
+
+	t.Run("HookError", func(t *testing.T) {
+		var calls int
+
+		invoker := InterceptorInvoker(func(ctx context.Context, req *Request, dst any) error {
+			calls++
+			return &Error{
+				Code:       400,
+				Parameters: &ResponseParameters{MigrateToChatID: -100123},
+			}
+		})
+
+		interceptor := NewInterceptorChatMigration(
+			WithInterceptorChatMigrationHook(func(ctx context.Context, from, to ChatID) error {
+				return errors.New("test")
+			}),
+		)
+
+		err := interceptor(context.Background(), NewRequest("sendMessage").String("chat_id", "-123"), nil, invoker)
+
+		require.EqualError(t, err, "chat migration hook: test")
+		assert.Equal(t, 1, calls, "should call invoker once")
+	})
+
+	t.Run("FromChatID", func(t *testing.T) {
+		var calls int
+
+		invoker := InterceptorInvoker(func(ctx context.Context, req *Request, dst any) error {
+			calls++
+			return &Error{
+				Code:       400,
+				Message:    "Bad Request: group chat was upgraded to a supergroup chat",
+				Parameters: &ResponseParameters{MigrateToChatID: -100123},
+			}
+		})
+
+		var hookCalls int
+
+		interceptor := NewInterceptorChatMigration(
+			WithInterceptorChatMigrationHook(func(ctx context.Context, from, to ChatID) error {
+				hookCalls++
+				return nil
+			}),
+		)
+
+		req := NewRequest("forwardMessage").
+			String("chat_id", "1").
+			String("from_chat_id", "-123").
+			Int("message_id", 1)
+
+		err := interceptor(context.Background(), req, nil, invoker)
+
+		require.ErrorIs(t, err, ErrGroupMigrated)
+		assert.Equal(t, 1, calls, "should call invoker once")
+		assert.Equal(t, 0, hookCalls, "should not call hook")
+
+		chatID, _ := req.GetArg("chat_id")
+		assert.Equal(t, "1", chatID, "should not rewrite chat_id")
+	})
+}
@@ -0,0 +1,137 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TimeoutError is returned by [NewInterceptorTimeout] when the request exceeds its timeout.
+// It matches [context.DeadlineExceeded] with [errors.Is].
+type TimeoutError struct {
+	// Method of the request.
+	Method string
+
+	// Timeout applied to the request.
+	Timeout time.Duration
+
+	// Err is the error returned by the request.
+	Err error
+}
+
+func (err *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: request timeout after %v: %v", err.Method, err.Timeout, err.Err)
+}
+
+func (err *TimeoutError) Unwrap() error {
+	return err.Err
+}
+
+// Is reports whether the target is [context.DeadlineExceeded].
+func (err *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+type interceptorTimeoutOpts struct {
+	timeout       time.Duration
+	upload        time.Duration
+	methods       map[string]time.Duration
+	getUpdatesGap time.Duration
+}
+
+// InterceptorTimeoutOption is an option for NewInterceptorTimeout.
+type InterceptorTimeoutOption func(*interceptorTimeoutOpts)
+
+// WithInterceptorTimeoutDefault sets the timeout of requests without files.
+// Default is 30 seconds, zero disables timeout.
+func WithInterceptorTimeoutDefault(timeout time.Duration) InterceptorTimeoutOption {
+	return func(o *interceptorTimeoutOpts) {
+		o.timeout = timeout
+	}
+}
+
+// WithInterceptorTimeoutUpload sets the timeout of requests with uploaded files.
+// Default is 5 minutes, zero disables timeout.
+func WithInterceptorTimeoutUpload(timeout time.Duration) InterceptorTimeoutOption {
+	return func(o *interceptorTimeoutOpts) {
+		o.upload = timeout
+	}
+}
+
+// WithInterceptorTimeoutMethod sets the timeout of the method, regardless of files.
+// Zero disables timeout of the method.
+func WithInterceptorTimeoutMethod(method string, timeout time.Duration) InterceptorTimeoutOption {
+	return func(o *interceptorTimeoutOpts) {
+		o.methods[method] = timeout
+	}
+}
+
+// WithInterceptorTimeoutGetUpdatesGap sets the time added to timeout argument of getUpdates long polling.
+// Default is 10 seconds. It's not used if the timeout of getUpdates is set by [WithInterceptorTimeoutMethod].
+func WithInterceptorTimeoutGetUpdatesGap(gap time.Duration) InterceptorTimeoutOption {
+	return func(o *interceptorTimeoutOpts) {
+		o.getUpdatesGap = gap
+	}
+}
+
+// NewInterceptorTimeout returns a new interceptor that limits duration of requests.
+//
+// Timeout of request is chosen by method (see [WithInterceptorTimeoutMethod]), files
+// (see [WithInterceptorTimeoutUpload]) or default one (see [WithInterceptorTimeoutDefault]).
+// Long polling getUpdates gets its timeout argument plus gap (see [WithInterceptorTimeoutGetUpdatesGap]).
+//
+// If the request exceeds timeout, [TimeoutError] is returned. Cancellation or deadline of caller's context
+// is returned as is. Register it after retrying interceptors to limit each try, or before to limit the whole call.
+func NewInterceptorTimeout(opts ...InterceptorTimeoutOption) Interceptor {
+	options := &interceptorTimeoutOpts{
+		timeout:       30 * time.Second,
+		upload:        5 * time.Minute,
+		methods:       make(map[string]time.Duration),
+		getUpdatesGap: 10 * time.Second,
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+		timeout := options.timeoutOf(req)
+		if timeout <= 0 {
+			return invoker(ctx, req, dst)
+		}
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err := invoker(timeoutCtx, req, dst)
+		if err != nil && ctx.Err() == nil && timeoutCtx.Err() != nil {
+			return &TimeoutError{
+				Method:  req.Method,
+				Timeout: timeout,
+				Err:     err,
+			}
+		}
+
+		return err
+	}
+}
+
+// timeoutOf returns the timeout of the request.
+func (options *interceptorTimeoutOpts) timeoutOf(req *Request) time.Duration {
+	if timeout, ok := options.methods[req.Method]; ok {
+		return timeout
+	}
+
+	if req.Method == "getUpdates" {
+		seconds, _ := req.GetArg("timeout")
+		n, _ := strconv.Atoi(seconds)
+		return time.Duration(n)*time.Second + options.getUpdatesGap
+	}
+
+	if len(req.files) > 0 {
+		return options.upload
+	}
+
+	return options.timeout
+}
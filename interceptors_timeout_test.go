@@ -0,0 +1,109 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInterceptorTimeout(t *testing.T) {
+	deadlineOf := func(t *testing.T, interceptor Interceptor, req *Request) time.Duration {
+		t.Helper()
+
+		var timeout time.Duration
+
+		start := time.Now()
+
+		err := interceptor(context.Background(), req, nil, func(ctx context.Context, req *Request, dst any) error {
+			deadline, ok := ctx.Deadline()
+			if ok {
+				timeout = deadline.Sub(start).Round(time.Second)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		return timeout
+	}
+
+	t.Run("Timeouts", func(t *testing.T) {
+		interceptor := NewInterceptorTimeout(
+			WithInterceptorTimeoutDefault(10*time.Second),
+			WithInterceptorTimeoutUpload(time.Minute),
+			WithInterceptorTimeoutMethod("sendMessage", 5*time.Second),
+			WithInterceptorTimeoutMethod("sendDocument", 0),
+		)
+
+		assert.Equal(t, 10*time.Second, deadlineOf(t, interceptor, NewRequest("getMe")))
+		assert.Equal(t, 5*time.Second, deadlineOf(t, interceptor, NewRequest("sendMessage")))
+		assert.Equal(t, time.Minute, deadlineOf(t, interceptor,
+			NewRequest("sendPhoto").InputFile("photo", NewInputFileBytes("photo.jpg", []byte("photo"))),
+		))
+		assert.Zero(t, deadlineOf(t, interceptor,
+			NewRequest("sendDocument").InputFile("document", NewInputFileBytes("file.txt", []byte("file"))),
+		), "should disable timeout")
+		assert.Equal(t, 50*time.Second, deadlineOf(t, interceptor, NewRequest("getUpdates").Int("timeout", 40)))
+	})
+
+	t.Run("TimeoutError", func(t *testing.T) {
+		interceptor := NewInterceptorTimeout(
+			WithInterceptorTimeoutDefault(time.Millisecond),
+		)
+
+		err := interceptor(context.Background(), NewRequest("getMe"), nil, func(ctx context.Context, req *Request, dst any) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, "getMe", timeoutErr.Method)
+		assert.Equal(t, time.Millisecond, timeoutErr.Timeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, "getMe: request timeout after 1ms: context deadline exceeded", err.Error())
+	})
+
+	t.Run("CallerContext", func(t *testing.T) {
+		interceptor := NewInterceptorTimeout()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := interceptor(ctx, NewRequest("getMe"), nil, func(ctx context.Context, req *Request, dst any) error {
+			return ctx.Err()
+		})
+
+		require.ErrorIs(t, err, context.Canceled)
+
+		var timeoutErr *TimeoutError
+		assert.False(t, errors.As(err, &timeoutErr), "should not wrap caller's cancellation")
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		var calls int
+
+		retry := NewInterceptorRetry(
+			WithInterceptorRetryPolicy(RetryOnTimeout()),
+			WithInterceptorRetryBackoff(RetryBackoffConstant(0)),
+		)
+
+		timeout := NewInterceptorTimeout(WithInterceptorTimeoutDefault(time.Millisecond))
+
+		err := retry(context.Background(), NewRequest("getMe"), nil, func(ctx context.Context, req *Request, dst any) error {
+			return timeout(ctx, req, dst, func(ctx context.Context, req *Request, dst any) error {
+				calls++
+				if calls == 1 {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				return nil
+			})
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,256 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// UploadCache stores identifiers of uploaded files by content key.
+// Implementations must be safe for concurrent use.
+type UploadCache interface {
+	// Get returns file identifier by key.
+	// Returns empty FileID if key is not found.
+	Get(ctx context.Context, key string) (FileID, error)
+
+	// Set saves file identifier by key.
+	Set(ctx context.Context, key string, id FileID) error
+}
+
+// UploadCacheMemory is an in-memory implementation of [UploadCache].
+type UploadCacheMemory struct {
+	lock  sync.RWMutex
+	items map[string]FileID
+}
+
+var _ UploadCache = (*UploadCacheMemory)(nil)
+
+// NewUploadCacheMemory creates a new in-memory upload cache.
+func NewUploadCacheMemory() *UploadCacheMemory {
+	return &UploadCacheMemory{
+		items: make(map[string]FileID),
+	}
+}
+
+func (cache *UploadCacheMemory) Get(ctx context.Context, key string) (FileID, error) {
+	cache.lock.RLock()
+	defer cache.lock.RUnlock()
+
+	return cache.items[key], nil
+}
+
+func (cache *UploadCacheMemory) Set(ctx context.Context, key string, id FileID) error {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.items[key] = id
+
+	return nil
+}
+
+// uploadCacheKinds is a set of top-level request fields which accept file_id.
+var uploadCacheKinds = map[string]struct{}{
+	"photo":      {},
+	"audio":      {},
+	"document":   {},
+	"video":      {},
+	"animation":  {},
+	"voice":      {},
+	"video_note": {},
+	"sticker":    {},
+}
+
+// uploadCacheEntry is a file of request that can be cached.
+type uploadCacheEntry struct {
+	// name of file in request
+	field string
+
+	// cache key
+	key string
+
+	// name of InputMedia JSON argument, empty if file is top-level field
+	media string
+
+	// index of media in media group, used to find result message
+	index int
+}
+
+type interceptorUploadCacheOpts struct {
+	onError func(ctx context.Context, err error)
+}
+
+// InterceptorUploadCacheOption is an option for NewInterceptorUploadCache.
+type InterceptorUploadCacheOption func(*interceptorUploadCacheOpts)
+
+// WithInterceptorUploadCacheOnError sets the function called on cache errors.
+// Cache errors are not returned to the caller, request is performed as if cache is missed.
+func WithInterceptorUploadCacheOnError(onError func(ctx context.Context, err error)) InterceptorUploadCacheOption {
+	return func(o *interceptorUploadCacheOpts) {
+		o.onError = onError
+	}
+}
+
+// NewInterceptorUploadCache returns a new interceptor that avoids uploading the same content twice.
+//
+// Content of uploaded files is hashed (sha256) and the file_id from the returned [Message] is saved to cache.
+// Next requests with the same content are sent with file_id instead of upload.
+// Both top-level files (sendPhoto, sendDocument, etc.) and [InputMedia] attachments (sendMediaGroup, editMessageMedia) are supported.
+//
+// Only files which body can be read twice are cached (see [InputFile] rewind notes), other files are uploaded as is.
+// Thumbnails are never cached, because Telegram doesn't allow to reuse them.
+func NewInterceptorUploadCache(cache UploadCache, opts ...InterceptorUploadCacheOption) Interceptor {
+	options := &interceptorUploadCacheOpts{
+		onError: func(ctx context.Context, err error) {},
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+		if len(req.files) == 0 || !(IsSendMethod(req.Method) || req.Method == "editMessageMedia") {
+			return invoker(ctx, req, dst)
+		}
+
+		entries, err := uploadCacheEntries(req)
+		if err != nil {
+			return err
+		}
+
+		pending := make([]uploadCacheEntry, 0, len(entries))
+
+		for _, entry := range entries {
+			id, err := cache.Get(ctx, entry.key)
+			if err != nil {
+				options.onError(ctx, fmt.Errorf("upload cache get: %w", err))
+			}
+
+			if id == "" {
+				pending = append(pending, entry)
+				continue
+			}
+
+			delete(req.files, entry.field)
+
+			if entry.media != "" {
+				req.replaceInputMedia(entry.media, entry.index, id)
+			} else {
+				req.FileID(entry.field, id)
+			}
+		}
+
+		if err := invoker(ctx, req, dst); err != nil {
+			return err
+		}
+
+		for _, entry := range pending {
+			id := uploadCacheResult(dst, entry)
+			if id == "" {
+				continue
+			}
+
+			if err := cache.Set(ctx, entry.key, id); err != nil {
+				options.onError(ctx, fmt.Errorf("upload cache set: %w", err))
+			}
+		}
+
+		return nil
+	}
+}
+
+// uploadCacheEntries returns files of request which can be replaced by file_id.
+func uploadCacheEntries(req *Request) ([]uploadCacheEntry, error) {
+	entries := make([]uploadCacheEntry, 0, len(req.files))
+
+	for field, file := range req.files {
+		entry := uploadCacheEntry{field: field}
+
+		var kind string
+
+		if strings.HasPrefix(field, "attachment_") {
+			name, index, im, ok := req.findInputMedia("attach://" + field)
+			if !ok {
+				continue
+			}
+
+			kind = im.kind()
+			entry.media = name
+			entry.index = index
+		} else if _, ok := uploadCacheKinds[field]; ok {
+			kind = field
+		} else {
+			continue
+		}
+
+		if !file.isRewindable() {
+			continue
+		}
+
+		sum, err := file.digest(req.filesConsumed)
+		if err != nil {
+			return nil, fmt.Errorf("digest file %s: %w", field, err)
+		}
+		req.files[field] = file
+
+		entry.key = kind + ":" + sum
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// uploadCacheResult returns file_id of uploaded entry from the result of request.
+func uploadCacheResult(dst any, entry uploadCacheEntry) FileID {
+	switch result := dst.(type) {
+	case *Message:
+		return result.FileID()
+	case *[]Message:
+		if entry.index < len(*result) {
+			return (*result)[entry.index].FileID()
+		}
+	}
+
+	return ""
+}
+
+// findInputMedia returns the InputMedia with media pointing to addr,
+// name of JSON argument and index of media in media group.
+func (r *Request) findInputMedia(addr string) (name string, index int, im InputMedia, ok bool) {
+	match := func(im *InputMedia) bool {
+		media, _ := im.getMedia()
+		return media != nil && media.addr == addr
+	}
+
+	for name, v := range r.json {
+		switch v := v.(type) {
+		case InputMedia:
+			if match(&v) {
+				return name, 0, v, true
+			}
+		case []InputMedia:
+			for i := range v {
+				if match(&v[i]) {
+					return name, i, v[i], true
+				}
+			}
+		}
+	}
+
+	return "", 0, InputMedia{}, false
+}
+
+// replaceInputMedia replaces media of InputMedia JSON argument by file identifier.
+// Values passed by caller are not modified, request holds own copy of them.
+func (r *Request) replaceInputMedia(name string, index int, id FileID) {
+	switch v := r.json[name].(type) {
+	case InputMedia:
+		r.json[name] = v.withFileID(id)
+	case []InputMedia:
+		items := slices.Clone(v)
+		items[index] = items[index].withFileID(id)
+		r.json[name] = items
+	}
+}
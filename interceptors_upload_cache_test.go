@@ -0,0 +1,250 @@
+package tg
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInterceptorUploadCache(t *testing.T) {
+	contentKey := func(kind string, content []byte) string {
+		sum := sha256.Sum256(content)
+		return kind + ":" + hex.EncodeToString(sum[:])
+	}
+
+	// upload reads files of request like real client does
+	upload := func(t *testing.T, req *Request) {
+		t.Helper()
+
+		require.NoError(t, req.rewindFiles())
+
+		for _, file := range req.files {
+			_, err := io.ReadAll(file.Body)
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("TopLevel", func(t *testing.T) {
+		cache := NewUploadCacheMemory()
+		interceptor := NewInterceptorUploadCache(cache)
+
+		content := []byte("logo")
+
+		newRequest := func() *Request {
+			return NewRequest("sendPhoto").
+				String("chat_id", "1").
+				File("photo", NewFileArgUpload(NewInputFileBytes("logo.png", content)))
+		}
+
+		var msg Message
+		err := interceptor(context.Background(), newRequest(), &msg, func(ctx context.Context, req *Request, dst any) error {
+			assert.Contains(t, req.files, "photo")
+			upload(t, req)
+			dst.(*Message).Photo = []PhotoSize{{FileID: "small"}, {FileID: "big"}}
+			return nil
+		})
+		require.NoError(t, err)
+
+		id, err := cache.Get(context.Background(), contentKey("photo", content))
+		require.NoError(t, err)
+		assert.Equal(t, FileID("big"), id)
+
+		err = interceptor(context.Background(), newRequest(), &msg, func(ctx context.Context, req *Request, dst any) error {
+			assert.Empty(t, req.files)
+			arg, _ := req.GetArg("photo")
+			assert.Equal(t, "big", arg)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("MediaGroup", func(t *testing.T) {
+		cache := NewUploadCacheMemory()
+		interceptor := NewInterceptorUploadCache(cache)
+
+		newMedia := func() []InputMedia {
+			return []InputMedia{
+				{Photo: &InputMediaPhoto{Media: NewFileArgID("existing")}},
+				{Photo: &InputMediaPhoto{Media: NewFileArgUpload(NewInputFileBytes("a.png", []byte("a")))}},
+				{Document: &InputMediaDocument{Media: NewFileArgUpload(NewInputFileBytes("b.txt", []byte("b")))}},
+			}
+		}
+
+		err := interceptor(context.Background(), NewRequest("sendMediaGroup").InputMediaSlice("media", newMedia()), &[]Message{}, func(ctx context.Context, req *Request, dst any) error {
+			assert.Len(t, req.files, 2)
+			upload(t, req)
+			*dst.(*[]Message) = []Message{
+				{Photo: []PhotoSize{{FileID: "existing"}}},
+				{Photo: []PhotoSize{{FileID: "a_id"}}},
+				{Document: &Document{FileID: "b_id"}},
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		id, err := cache.Get(context.Background(), contentKey("photo", []byte("a")))
+		require.NoError(t, err)
+		assert.Equal(t, FileID("a_id"), id)
+
+		id, err = cache.Get(context.Background(), contentKey("document", []byte("b")))
+		require.NoError(t, err)
+		assert.Equal(t, FileID("b_id"), id)
+
+		media := newMedia()
+
+		err = interceptor(context.Background(), NewRequest("sendMediaGroup").InputMediaSlice("media", media), &[]Message{}, func(ctx context.Context, req *Request, dst any) error {
+			assert.Empty(t, req.files)
+
+			require.NoError(t, req.jsonToArgs())
+			arg, _ := req.GetArg("media")
+			assert.JSONEq(t, `[
+				{"type":"photo","media":"existing"},
+				{"type":"photo","media":"a_id"},
+				{"type":"document","media":"b_id"}
+			]`, arg)
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, FileID(""), media[1].Photo.Media.FileID, "should not modify caller's media")
+		assert.Equal(t, "attach://attachment_0", media[1].Photo.Media.getRef())
+	})
+
+	t.Run("Thumbnail", func(t *testing.T) {
+		cache := NewUploadCacheMemory()
+		interceptor := NewInterceptorUploadCache(cache)
+
+		newRequest := func() *Request {
+			thumb := NewInputFileBytes("thumb.jpg", []byte("thumb"))
+
+			return NewRequest("editMessageMedia").InputMedia("media", InputMedia{
+				Video: &InputMediaVideo{
+					Media:     NewFileArgUpload(NewInputFileBytes("video.mp4", []byte("video"))),
+					Thumbnail: &thumb,
+				},
+			})
+		}
+
+		err := interceptor(context.Background(), newRequest(), &Message{}, func(ctx context.Context, req *Request, dst any) error {
+			upload(t, req)
+			dst.(*Message).Video = &Video{FileID: "video_id"}
+			return nil
+		})
+		require.NoError(t, err)
+
+		err = interceptor(context.Background(), newRequest(), &Message{}, func(ctx context.Context, req *Request, dst any) error {
+			assert.NotContains(t, req.files, "attachment_0")
+			assert.Contains(t, req.files, "attachment_0_thumb", "thumbnail should be uploaded")
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("NotRewindable", func(t *testing.T) {
+		cache := NewUploadCacheMemory()
+		interceptor := NewInterceptorUploadCache(cache)
+
+		req := NewRequest("sendDocument").
+			File("document", NewFileArgUpload(NewInputFile("doc.txt", io.MultiReader(bytes.NewReader([]byte("doc"))))))
+
+		err := interceptor(context.Background(), req, &Message{}, func(ctx context.Context, req *Request, dst any) error {
+			body, err := io.ReadAll(req.files["document"].Body)
+			require.NoError(t, err)
+			assert.Equal(t, "doc", string(body), "body should not be read by interceptor")
+
+			dst.(*Message).Document = &Document{FileID: "doc_id"}
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, cache.items)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		cache := NewUploadCacheMemory()
+		interceptor := NewInterceptorUploadCache(cache)
+
+		content := []byte("banner")
+
+		req := NewRequest("sendPhoto").
+			File("photo", NewFileArgUpload(NewInputFileBytes("banner.png", content)))
+
+		calls := 0
+		invoker := func(ctx context.Context, req *Request, dst any) error {
+			calls++
+			upload(t, req)
+
+			if calls == 1 {
+				return errors.New("internal server error")
+			}
+
+			dst.(*Message).Photo = []PhotoSize{{FileID: "banner_id"}}
+			return nil
+		}
+
+		// retry interceptor calls the rest of chain again with the same request
+		err := interceptor(context.Background(), req, &Message{}, invoker)
+		require.Error(t, err)
+
+		err = interceptor(context.Background(), req, &Message{}, invoker)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]FileID{
+			contentKey("photo", content): "banner_id",
+		}, cache.items)
+	})
+
+	t.Run("NotSendMethod", func(t *testing.T) {
+		cache := NewUploadCacheMemory()
+		require.NoError(t, cache.Set(context.Background(), contentKey("photo", []byte("avatar")), "avatar_id"))
+
+		interceptor := NewInterceptorUploadCache(cache)
+
+		req := NewRequest("setChatPhoto").
+			File("photo", NewFileArgUpload(NewInputFileBytes("avatar.png", []byte("avatar"))))
+
+		err := interceptor(context.Background(), req, nil, func(ctx context.Context, req *Request, dst any) error {
+			assert.Contains(t, req.files, "photo")
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("CacheError", func(t *testing.T) {
+		var cacheErr error
+
+		interceptor := NewInterceptorUploadCache(
+			uploadCacheFailing{},
+			WithInterceptorUploadCacheOnError(func(ctx context.Context, err error) {
+				cacheErr = err
+			}),
+		)
+
+		req := NewRequest("sendPhoto").
+			File("photo", NewFileArgUpload(NewInputFileBytes("logo.png", []byte("logo"))))
+
+		err := interceptor(context.Background(), req, &Message{}, func(ctx context.Context, req *Request, dst any) error {
+			assert.Contains(t, req.files, "photo")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.EqualError(t, cacheErr, "upload cache get: unavailable")
+	})
+}
+
+type uploadCacheFailing struct{}
+
+func (uploadCacheFailing) Get(ctx context.Context, key string) (FileID, error) {
+	return "", errors.New("unavailable")
+}
+
+func (uploadCacheFailing) Set(ctx context.Context, key string, id FileID) error {
+	return errors.New("unavailable")
+}
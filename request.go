@@ -1,9 +1,13 @@
 package tg
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"golang.org/x/exp/maps"
 )
@@ -17,6 +21,13 @@ type Request struct {
 	files map[string]InputFile
 
 	attachmentIdx int
+
+	// filesConsumed is true if files were read by previous upload attempt.
+	filesConsumed bool
+
+	// localAttachments maps attach:// address of file to its file:// URI,
+	// used by local Bot API server mode.
+	localAttachments map[string]string
 }
 
 func NewRequest(method string) *Request {
@@ -184,7 +195,7 @@ func (r *Request) Stringer(name string, v fmt.Stringer) *Request {
 
 func (r *Request) jsonToArgs() error {
 	for k, jn := range r.json {
-		v, err := json.Marshal(jn)
+		v, err := r.marshalJSONArg(jn)
 		if err != nil {
 			return fmt.Errorf("failed to marshal %s: %w", k, err)
 		}
@@ -194,10 +205,83 @@ func (r *Request) jsonToArgs() error {
 	return nil
 }
 
+// marshalJSONArg marshals JSON argument of request.
+// Addresses of attachments replaced by local files are rewritten to file URIs.
+func (r *Request) marshalJSONArg(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(r.localAttachments) == 0 {
+		return data, err
+	}
+
+	for addr, uri := range r.localAttachments {
+		from, err := json.Marshal(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := json.Marshal(uri)
+		if err != nil {
+			return nil, err
+		}
+
+		data = bytes.ReplaceAll(data, from, to)
+	}
+
+	return data, nil
+}
+
+// useLocalFiles replaces files created by [NewInputFileLocal] with file URIs.
+// Local Bot API server reads such files directly from disk, so they are not uploaded.
+func (r *Request) useLocalFiles() {
+	for name, file := range r.files {
+		if file.path == "" {
+			continue
+		}
+
+		uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(file.path)}).String()
+
+		delete(r.files, name)
+
+		if strings.HasPrefix(name, "attachment_") {
+			if r.localAttachments == nil {
+				r.localAttachments = make(map[string]string)
+			}
+
+			r.localAttachments["attach://"+name] = uri
+		} else {
+			r.String(name, uri)
+		}
+	}
+}
+
+// rewindFiles prepares files for upload.
+// If files were already read by previous attempt (e.g. request is retried by interceptor),
+// they are rewound to the beginning.
+func (r *Request) rewindFiles() error {
+	if !r.filesConsumed {
+		r.filesConsumed = true
+		return nil
+	}
+
+	for k, file := range r.files {
+		if err := file.rewind(); err != nil {
+			return fmt.Errorf("rewind file %s: %w", k, err)
+		}
+
+		r.files[k] = file
+	}
+
+	return nil
+}
+
 // Encode request using encoder.
 func (r *Request) Encode(encoder Encoder) error {
-	if err := r.jsonToArgs(); err != nil {
-		return fmt.Errorf("encode json to args: %w", err)
+	jsonEncoder, isJSON := encoder.(jsonValueEncoder)
+
+	if !isJSON {
+		if err := r.jsonToArgs(); err != nil {
+			return fmt.Errorf("encode json to args: %w", err)
+		}
 	}
 
 	// add files
@@ -207,8 +291,27 @@ func (r *Request) Encode(encoder Encoder) error {
 		}
 	}
 
+	// add json arguments as is
+	if isJSON {
+		for k, v := range r.json {
+			data, err := r.marshalJSONArg(v)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s: %w", k, err)
+			}
+
+			if err := jsonEncoder.WriteJSON(k, data); err != nil {
+				return fmt.Errorf("encode argument %s: %w", k, err)
+			}
+		}
+	}
+
 	// add arguments
 	for k, v := range r.args {
+		// already encoded as json (args can contain it after previous jsonToArgs call)
+		if _, ok := r.json[k]; isJSON && ok {
+			continue
+		}
+
 		if err := encoder.WriteString(k, v); err != nil {
 			return fmt.Errorf("encode argument %s: %w", k, err)
 		}
@@ -0,0 +1,139 @@
+package tg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type curlOpts struct {
+	token bool
+}
+
+// CurlOption is an option for Request.Curl and NewInterceptorCurl.
+type CurlOption func(*curlOpts)
+
+// WithCurlToken includes the bot token into URL of command.
+// By default it's replaced with placeholder, so command can be logged safely.
+func WithCurlToken() CurlOption {
+	return func(o *curlOpts) {
+		o.token = true
+	}
+}
+
+// Curl renders the request as curl command for client, e.g. to reproduce failed request by hand.
+//
+// Arguments are sorted by name. Requests with files are rendered as multipart/form-data:
+// files created by [NewInputFileLocal] are referenced by path, other ones by name of file,
+// so they should be placed in working directory. Requests without files are rendered as
+// application/x-www-form-urlencoded.
+//
+//	curl -sS -X POST 'https://api.telegram.org/bot<redacted>/sendMessage' --data-urlencode 'chat_id=1' --data-urlencode 'text=hello'
+func (r *Request) Curl(client *Client, opts ...CurlOption) (string, error) {
+	options := &curlOpts{}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	record, err := r.Record()
+	if err != nil {
+		return "", fmt.Errorf("record request: %w", err)
+	}
+
+	url := client.buildCallURL(client.token, r.Method)
+	if !options.token {
+		url = client.Redact(url)
+	}
+
+	parts := []string{"curl", "-sS", "-X", "POST", shellQuote(url)}
+
+	flag := "--data-urlencode"
+	if len(r.files) > 0 {
+		// unlike -F, --form-string doesn't treat values starting with @ and < as files
+		flag = "--form-string"
+	}
+
+	names := make([]string, 0, len(record.Args))
+	for name := range record.Args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		parts = append(parts, flag, shellQuote(name+"="+record.Args[name]))
+	}
+
+	names = names[:0]
+	for name := range r.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		file := r.files[name]
+
+		path := file.path
+		if path == "" {
+			path = file.Name
+		}
+
+		parts = append(parts, "-F", shellQuote(fmt.Sprintf("%s=@%s;filename=%s", name, curlFormQuote(path), curlFormQuote(file.Name))))
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// shellQuote quotes s for POSIX shell.
+// curlFormQuote quotes value of -F option if it contains characters special for curl, e.g. ';' separating parameters.
+// Inside double quotes curl treats backslash as escape character.
+func curlFormQuote(value string) string {
+	if !strings.ContainsAny(value, "\";,\\\r\n") && strings.TrimSpace(value) == value {
+		return value
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// clientContextKey is the context key of Client making the request.
+type clientContextKey struct{}
+
+// clientFromContext returns the Client making the request, set by [Client.Do].
+func clientFromContext(ctx context.Context) (*Client, bool) {
+	client, ok := ctx.Value(clientContextKey{}).(*Client)
+	return client, ok
+}
+
+// NewInterceptorCurl returns a new interceptor that renders failed requests as curl commands
+// (see [Request.Curl]) and passes them to log with the error.
+//
+//	tg.NewInterceptorCurl(func(ctx context.Context, cmd string, err error) {
+//	  slog.ErrorContext(ctx, "request failed", "error", err, "curl", cmd)
+//	})
+func NewInterceptorCurl(log func(ctx context.Context, cmd string, err error), opts ...CurlOption) Interceptor {
+	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+		err := invoker(ctx, req, dst)
+		if err == nil {
+			return nil
+		}
+
+		client, ok := clientFromContext(ctx)
+		if !ok {
+			return err
+		}
+
+		cmd, curlErr := req.Curl(client, opts...)
+		if curlErr != nil {
+			cmd = fmt.Sprintf("# can't render request: %v", curlErr)
+		}
+
+		log(ctx, cmd, err)
+
+		return err
+	}
+}
@@ -0,0 +1,117 @@
+package tg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequest_Curl(t *testing.T) {
+	client := New("1234:secret")
+
+	t.Run("Args", func(t *testing.T) {
+		req := NewRequest("sendMessage").
+			ChatID("chat_id", 1).
+			String("text", "it's @me").
+			JSON("reply_markup", map[string]any{"remove_keyboard": true})
+
+		cmd, err := req.Curl(client)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			`curl -sS -X POST 'https://api.telegram.org/bot<redacted>/sendMessage'`+
+				` --data-urlencode 'chat_id=1'`+
+				` --data-urlencode 'reply_markup={"remove_keyboard":true}'`+
+				` --data-urlencode 'text=it'\''s @me'`,
+			cmd,
+		)
+	})
+
+	t.Run("Token", func(t *testing.T) {
+		cmd, err := NewRequest("getMe").Curl(client, WithCurlToken())
+		require.NoError(t, err)
+
+		assert.Equal(t, `curl -sS -X POST 'https://api.telegram.org/bot1234:secret/getMe'`, cmd)
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "photo.jpg")
+		require.NoError(t, os.WriteFile(path, []byte("photo"), 0o600))
+
+		local, err := NewInputFileLocal(path)
+		require.NoError(t, err)
+		defer local.Close()
+
+		req := NewRequest("sendMediaGroup").
+			ChatID("chat_id", 1).
+			InputMediaSlice("media", []InputMedia{
+				{Photo: &InputMediaPhoto{Media: FileArg{Upload: local}}},
+				{Document: &InputMediaDocument{Media: FileArg{Upload: NewInputFileBytes("doc.txt", []byte("doc"))}}},
+			})
+
+		cmd, err := req.Curl(client)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			`curl -sS -X POST 'https://api.telegram.org/bot<redacted>/sendMediaGroup'`+
+				` --form-string 'chat_id=1'`+
+				` --form-string 'media=[{"type":"photo","media":"attach://attachment_0"},{"type":"document","media":"attach://attachment_1"}]'`+
+				` -F 'attachment_0=@`+path+`;filename=photo.jpg'`+
+				` -F 'attachment_1=@doc.txt;filename=doc.txt'`,
+			cmd,
+		)
+	})
+
+	t.Run("FileSpecialName", func(t *testing.T) {
+		req := NewRequest("sendDocument").
+			InputFile("document", NewInputFileBytes("a\"b;type=text/html.txt", []byte("doc")))
+
+		cmd, err := req.Curl(client)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			`curl -sS -X POST 'https://api.telegram.org/bot<redacted>/sendDocument'`+
+				` -F 'document=@"a\"b;type=text/html.txt";filename="a\"b;type=text/html.txt"'`,
+			cmd,
+		)
+	})
+}
+
+func TestNewInterceptorCurl(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bot1234:secret/getMe" {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1}}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+	}))
+	defer ts.Close()
+
+	var cmds []string
+
+	client := New("1234:secret",
+		WithClientServerURL(ts.URL),
+		WithClientInterceptors(NewInterceptorCurl(func(ctx context.Context, cmd string, err error) {
+			assert.Error(t, err)
+			cmds = append(cmds, cmd)
+		})),
+	)
+
+	_, err := client.GetMe().Do(context.Background())
+	require.NoError(t, err)
+
+	err = client.SendMessage(ChatID(1), "hello").DoVoid(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, []string{
+		`curl -sS -X POST '` + ts.URL + `/bot<redacted>/sendMessage' --data-urlencode 'chat_id=1' --data-urlencode 'text=hello'`,
+	}, cmds)
+}
@@ -0,0 +1,59 @@
+package tg
+
+import "fmt"
+
+// RequestRecord is a snapshot of request, e.g. for logging or auditing.
+type RequestRecord struct {
+	// Method of the request.
+	Method string `json:"method"`
+
+	// Args of the request by name, JSON arguments are encoded.
+	Args map[string]string `json:"args,omitempty"`
+
+	// Files of the request by argument name.
+	// Files of media groups are named attachment_N, as referenced by attach:// in JSON arguments.
+	Files map[string]RequestRecordFile `json:"files,omitempty"`
+}
+
+// RequestRecordFile is metadata of uploaded file.
+type RequestRecordFile struct {
+	// Name of the file.
+	Name string `json:"name"`
+
+	// Size of the file in bytes, -1 if it can't be determined without reading.
+	Size int64 `json:"size"`
+
+	// Optional. Hex encoded SHA-256 of the file content.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Record returns the snapshot of request. Bodies of files are not read.
+func (r *Request) Record() (*RequestRecord, error) {
+	record := &RequestRecord{
+		Method: r.Method,
+		Args:   make(map[string]string, len(r.args)+len(r.json)),
+		Files:  make(map[string]RequestRecordFile, len(r.files)),
+	}
+
+	for k, v := range r.args {
+		record.Args[k] = v
+	}
+
+	for k, v := range r.json {
+		data, err := r.marshalJSONArg(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", k, err)
+		}
+
+		record.Args[k] = string(data)
+	}
+
+	for k, file := range r.files {
+		record.Files[k] = RequestRecordFile{
+			Name: file.Name,
+			Size: file.size(),
+		}
+	}
+
+	return record, nil
+}
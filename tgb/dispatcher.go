@@ -0,0 +1,161 @@
+package tgb
+
+import (
+	"context"
+	"sync"
+)
+
+// DispatcherKeyFunc returns the key of update.
+// Updates with the same key are handled sequentially in order of arrival,
+// zero key means the update can be handled in parallel with any other.
+type DispatcherKeyFunc func(update *Update) int64
+
+// DispatcherKeyChat keys updates by chat, see [tg.Update.ChatID].
+func DispatcherKeyChat(update *Update) int64 {
+	return int64(update.ChatID())
+}
+
+// DispatcherKeyUser keys updates by user, see [tg.Update.User].
+func DispatcherKeyUser(update *Update) int64 {
+	if user := update.User(); user != nil {
+		return int64(user.ID)
+	}
+
+	return 0
+}
+
+// Dispatcher runs handling of updates with bounded concurrency,
+// preserving order of updates with the same key (e.g. from the same chat) and parallelizing others.
+//
+// Use it with [WithPollerDispatcher] or [WithWebhookDispatcher].
+type Dispatcher struct {
+	key     DispatcherKeyFunc
+	workers chan struct{}
+
+	lock   sync.Mutex
+	queues map[int64][]func()
+
+	wg sync.WaitGroup
+}
+
+// DispatcherOption is an option for NewDispatcher.
+type DispatcherOption func(*Dispatcher)
+
+// WithDispatcherWorkers sets max number of concurrently handled updates.
+// Default is 16.
+func WithDispatcherWorkers(workers int) DispatcherOption {
+	return func(dispatcher *Dispatcher) {
+		dispatcher.workers = make(chan struct{}, workers)
+	}
+}
+
+// WithDispatcherKey sets the key function of updates.
+// Default is [DispatcherKeyChat].
+func WithDispatcherKey(key DispatcherKeyFunc) DispatcherOption {
+	return func(dispatcher *Dispatcher) {
+		dispatcher.key = key
+	}
+}
+
+const defaultDispatcherWorkers = 16
+
+// NewDispatcher creates a new Dispatcher.
+func NewDispatcher(opts ...DispatcherOption) *Dispatcher {
+	dispatcher := &Dispatcher{
+		key:     DispatcherKeyChat,
+		workers: make(chan struct{}, defaultDispatcherWorkers),
+		queues:  make(map[int64][]func()),
+	}
+
+	for _, opt := range opts {
+		opt(dispatcher)
+	}
+
+	return dispatcher
+}
+
+// Dispatch schedules task handling the update.
+//
+// If update has the same key as one of scheduled updates, the task is queued after them.
+// Otherwise Dispatch waits for free worker, so callers are slowed down when all workers are busy.
+// Returns context error if ctx is done while waiting.
+func (dispatcher *Dispatcher) Dispatch(ctx context.Context, update *Update, task func()) error {
+	key := dispatcher.key(update)
+
+	if key != 0 {
+		dispatcher.lock.Lock()
+		if queue, ok := dispatcher.queues[key]; ok {
+			dispatcher.queues[key] = append(queue, task)
+			dispatcher.wg.Add(1)
+			dispatcher.lock.Unlock()
+			return nil
+		}
+		dispatcher.lock.Unlock()
+	}
+
+	select {
+	case dispatcher.workers <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if key != 0 {
+		dispatcher.lock.Lock()
+		// queue could be created while waiting for worker
+		if queue, ok := dispatcher.queues[key]; ok {
+			dispatcher.queues[key] = append(queue, task)
+			dispatcher.wg.Add(1)
+			dispatcher.lock.Unlock()
+			<-dispatcher.workers
+			return nil
+		}
+		dispatcher.queues[key] = []func(){}
+		dispatcher.lock.Unlock()
+	}
+
+	dispatcher.wg.Add(1)
+
+	go func() {
+		defer func() { <-dispatcher.workers }()
+
+		dispatcher.run(task)
+
+		if key != 0 {
+			dispatcher.drain(key)
+		}
+	}()
+
+	return nil
+}
+
+// drain runs queued tasks of the key until queue is empty.
+func (dispatcher *Dispatcher) drain(key int64) {
+	for {
+		dispatcher.lock.Lock()
+
+		queue := dispatcher.queues[key]
+		if len(queue) == 0 {
+			delete(dispatcher.queues, key)
+			dispatcher.lock.Unlock()
+			return
+		}
+
+		task := queue[0]
+		dispatcher.queues[key] = queue[1:]
+
+		dispatcher.lock.Unlock()
+
+		dispatcher.run(task)
+	}
+}
+
+func (dispatcher *Dispatcher) run(task func()) {
+	defer dispatcher.wg.Done()
+
+	task()
+}
+
+// Wait waits until all dispatched tasks are done.
+func (dispatcher *Dispatcher) Wait() {
+	dispatcher.wg.Wait()
+}
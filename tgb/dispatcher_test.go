@@ -0,0 +1,118 @@
+package tgb
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tg "github.com/mr-linch/go-tg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDispatcherTestUpdate(id int, chatID tg.ChatID) *Update {
+	return &Update{Update: &tg.Update{
+		ID:      id,
+		Message: &tg.Message{Chat: tg.Chat{ID: chatID}},
+	}}
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("Order", func(t *testing.T) {
+		dispatcher := NewDispatcher(WithDispatcherWorkers(4))
+
+		var (
+			lock    sync.Mutex
+			handled = make(map[tg.ChatID][]int)
+		)
+
+		for i := 1; i <= 50; i++ {
+			update := newDispatcherTestUpdate(i, tg.ChatID(i%3+1))
+
+			err := dispatcher.Dispatch(context.Background(), update, func() {
+				time.Sleep(time.Millisecond)
+
+				lock.Lock()
+				defer lock.Unlock()
+				handled[update.ChatID()] = append(handled[update.ChatID()], update.ID)
+			})
+			require.NoError(t, err)
+		}
+
+		dispatcher.Wait()
+
+		for chatID, ids := range handled {
+			assert.IsIncreasing(t, ids, "updates of chat %d should be handled in order", chatID)
+		}
+		assert.Len(t, handled[1], 16)
+		assert.Len(t, handled[2], 17)
+		assert.Len(t, handled[3], 17)
+	})
+
+	t.Run("Workers", func(t *testing.T) {
+		dispatcher := NewDispatcher(WithDispatcherWorkers(2))
+
+		var running, maxRunning atomic.Int32
+
+		for i := 1; i <= 20; i++ {
+			// different chats and updates without chat
+			update := newDispatcherTestUpdate(i, tg.ChatID(i%5))
+
+			err := dispatcher.Dispatch(context.Background(), update, func() {
+				n := running.Add(1)
+				defer running.Add(-1)
+
+				for {
+					max := maxRunning.Load()
+					if n <= max || maxRunning.CompareAndSwap(max, n) {
+						break
+					}
+				}
+
+				time.Sleep(time.Millisecond)
+			})
+			require.NoError(t, err)
+		}
+
+		dispatcher.Wait()
+
+		assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	})
+
+	t.Run("KeyUser", func(t *testing.T) {
+		update := &Update{Update: &tg.Update{
+			CallbackQuery: &tg.CallbackQuery{From: tg.User{ID: 10}},
+		}}
+
+		assert.Equal(t, int64(10), DispatcherKeyUser(update))
+		assert.Equal(t, int64(0), DispatcherKeyUser(&Update{Update: &tg.Update{}}))
+	})
+
+	t.Run("ContextDone", func(t *testing.T) {
+		dispatcher := NewDispatcher(WithDispatcherWorkers(1))
+
+		release := make(chan struct{})
+
+		err := dispatcher.Dispatch(context.Background(), newDispatcherTestUpdate(1, 1), func() {
+			<-release
+		})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err = dispatcher.Dispatch(ctx, newDispatcherTestUpdate(2, 2), func() {
+			t.Error("should not be called")
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// same chat is queued without waiting for worker
+		err = dispatcher.Dispatch(ctx, newDispatcherTestUpdate(3, 1), func() {})
+		require.NoError(t, err)
+
+		close(release)
+		dispatcher.Wait()
+	})
+}
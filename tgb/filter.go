@@ -311,6 +311,21 @@ func MessageType(types ...tg.MessageType) Filter {
 	})
 }
 
+// updateTypeFilter checks type of update.
+type updateTypeFilter struct {
+	types []tg.UpdateType
+}
+
+func (filter *updateTypeFilter) Allow(ctx context.Context, update *Update) (bool, error) {
+	return slices.Contains(filter.types, update.Type()), nil
+}
+
+// UpdateType checks if update is one of specified types.
+// Also declares types of updates received by [Router.Update] handler, see [Router.AllowedUpdates].
+func UpdateType(types ...tg.UpdateType) Filter {
+	return &updateTypeFilter{types: types}
+}
+
 // MessageEntity checks Message, EditedMessage, ChannelPost, EditedChannelPost .Entities, .CaptionEntities, .Poll.ExplanationEntities or .Game.TextEntities
 // for matching type with specified.
 // If multiple types are specified, it checks if message entity type is one of them.
@@ -519,6 +519,19 @@ func TestMessageType(t *testing.T) {
 	}
 }
 
+func TestUpdateType(t *testing.T) {
+	ctx := context.Background()
+	filter := UpdateType(tg.UpdateTypeMessage, tg.UpdateTypeCallbackQuery)
+
+	allow, err := filter.Allow(ctx, &Update{Update: &tg.Update{Message: &tg.Message{}}})
+	require.NoError(t, err)
+	assert.True(t, allow, "message should be allowed")
+
+	allow, err = filter.Allow(ctx, &Update{Update: &tg.Update{ChatMember: &tg.ChatMemberUpdated{}}})
+	require.NoError(t, err)
+	assert.False(t, allow, "chat member should not be allowed")
+}
+
 func TestTextFuncFilter(t *testing.T) {
 	newUpdateMsg := func(text string) *Update {
 		return &Update{Update: &tg.Update{
@@ -1,6 +1,31 @@
 package tgb
 
-// Logger defines generic interface for loggers
+import tg "github.com/mr-linch/go-tg"
+
+// Logger defines generic interface for loggers.
+// Bot token is removed from string and error arguments before logging.
 type Logger interface {
 	Printf(format string, args ...any)
 }
+
+// redactLogArgs removes the bot token from string and error arguments of log message.
+func redactLogArgs(client *tg.Client, args []any) []any {
+	if client == nil {
+		return args
+	}
+
+	result := make([]any, len(args))
+
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			result[i] = client.Redact(v)
+		case error:
+			result[i] = client.RedactError(v)
+		default:
+			result[i] = arg
+		}
+	}
+
+	return result
+}
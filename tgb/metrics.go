@@ -0,0 +1,111 @@
+package tgb
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	tg "github.com/mr-linch/go-tg"
+)
+
+// UpdateRecorder records metrics of handled updates.
+// Implementations must be safe for concurrent use.
+type UpdateRecorder interface {
+	// RecordUpdate is called after each update is handled.
+	// Lag is the time between message was sent and handling started, it's 0 if update has no message.
+	// Err is the error returned by handler.
+	RecordUpdate(ctx context.Context, updateType tg.UpdateType, duration, lag time.Duration, err error)
+}
+
+// UpdateRecorderExpvar is an [UpdateRecorder] which publishes metrics via [expvar].
+//
+// Metrics are grouped by update type:
+//
+//	{
+//	  "message": {
+//	    "count": 10,
+//	    "errors": 1,
+//	    "duration_seconds": 0.5,
+//	    "lag_seconds": 3
+//	  }
+//	}
+//
+// Where count is number of handled updates, errors is number of handler errors,
+// duration_seconds is total duration of handling and lag_seconds is total lag of updates.
+type UpdateRecorderExpvar struct {
+	lock  sync.Mutex
+	types *expvar.Map
+}
+
+var _ UpdateRecorder = (*UpdateRecorderExpvar)(nil)
+
+// NewUpdateRecorderExpvar creates a new recorder and publishes its metrics as expvar with given name.
+// Like [expvar.Publish], it panics if the name is already registered.
+func NewUpdateRecorderExpvar(name string) *UpdateRecorderExpvar {
+	return &UpdateRecorderExpvar{
+		types: expvar.NewMap(name),
+	}
+}
+
+func (recorder *UpdateRecorderExpvar) RecordUpdate(ctx context.Context, updateType tg.UpdateType, duration, lag time.Duration, err error) {
+	metrics := recorder.updateType(updateType.String())
+
+	metrics.Add("count", 1)
+	metrics.AddFloat("duration_seconds", duration.Seconds())
+	metrics.AddFloat("lag_seconds", lag.Seconds())
+
+	if err != nil {
+		metrics.Add("errors", 1)
+	}
+}
+
+// updateType returns metrics of update type, creating them if needed.
+func (recorder *UpdateRecorderExpvar) updateType(name string) *expvar.Map {
+	if metrics, ok := recorder.types.Get(name).(*expvar.Map); ok {
+		return metrics
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	if metrics, ok := recorder.types.Get(name).(*expvar.Map); ok {
+		return metrics
+	}
+
+	metrics := new(expvar.Map).Init()
+	metrics.Set("count", new(expvar.Int))
+	metrics.Set("errors", new(expvar.Int))
+	metrics.Set("duration_seconds", new(expvar.Float))
+	metrics.Set("lag_seconds", new(expvar.Float))
+
+	recorder.types.Set(name, metrics)
+
+	return metrics
+}
+
+// NewMetricsMiddleware returns a new middleware that records metrics of handled updates to recorder.
+// It's a counterpart of [tg.NewInterceptorMetrics] for incoming updates.
+//
+// Use it with [Router.Use], or wrap the handler passed to [Poller] or [Webhook]
+// to include routing into measurements:
+//
+//	handler := tgb.NewMetricsMiddleware(recorder).Wrap(router)
+func NewMetricsMiddleware(recorder UpdateRecorder) Middleware {
+	return MiddlewareFunc(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *Update) error {
+			start := time.Now()
+
+			var lag time.Duration
+			if msg := update.Msg(); msg != nil && !msg.Date.IsZero() {
+				lag = start.Sub(msg.Date.Time())
+			}
+
+			err := next.Handle(ctx, update)
+
+			recorder.RecordUpdate(ctx, update.Type(), time.Since(start), lag, err)
+
+			return err
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package tgb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	tg "github.com/mr-linch/go-tg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type updateRecorderMock struct {
+	updateType tg.UpdateType
+	lag        time.Duration
+	err        error
+}
+
+func (m *updateRecorderMock) RecordUpdate(ctx context.Context, updateType tg.UpdateType, duration, lag time.Duration, err error) {
+	m.updateType = updateType
+	m.lag = lag
+	m.err = err
+}
+
+func TestNewMetricsMiddleware(t *testing.T) {
+	t.Run("Lag", func(t *testing.T) {
+		recorder := &updateRecorderMock{}
+
+		handlerErr := errors.New("test")
+
+		handler := NewMetricsMiddleware(recorder).Wrap(HandlerFunc(func(ctx context.Context, update *Update) error {
+			return handlerErr
+		}))
+
+		err := handler.Handle(context.Background(), &Update{Update: &tg.Update{
+			Message: &tg.Message{Date: tg.UnixTime(time.Now().Add(-time.Minute).Unix())},
+		}})
+		require.ErrorIs(t, err, handlerErr)
+
+		assert.Equal(t, tg.UpdateTypeMessage, recorder.updateType)
+		assert.InDelta(t, time.Minute.Seconds(), recorder.lag.Seconds(), 1)
+		assert.Equal(t, handlerErr, recorder.err)
+	})
+
+	t.Run("NoMessage", func(t *testing.T) {
+		recorder := &updateRecorderMock{}
+
+		handler := NewMetricsMiddleware(recorder).Wrap(HandlerFunc(func(ctx context.Context, update *Update) error {
+			return nil
+		}))
+
+		err := handler.Handle(context.Background(), &Update{Update: &tg.Update{
+			CallbackQuery: &tg.CallbackQuery{},
+		}})
+		require.NoError(t, err)
+
+		assert.Equal(t, tg.UpdateTypeCallbackQuery, recorder.updateType)
+		assert.Zero(t, recorder.lag)
+	})
+}
+
+func TestUpdateRecorderExpvar(t *testing.T) {
+	name := fmt.Sprintf("tgb_test_updates_%d", time.Now().UnixNano())
+
+	recorder := NewUpdateRecorderExpvar(name)
+
+	recorder.RecordUpdate(context.Background(), tg.UpdateTypeMessage, time.Second, 2*time.Second, nil)
+	recorder.RecordUpdate(context.Background(), tg.UpdateTypeMessage, time.Second, time.Second, errors.New("test"))
+
+	var metrics map[string]map[string]float64
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &metrics))
+
+	assert.Equal(t, map[string]map[string]float64{
+		"message": {
+			"count":            2,
+			"errors":           1,
+			"duration_seconds": 2,
+			"lag_seconds":      3,
+		},
+	}, metrics)
+}
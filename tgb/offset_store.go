@@ -0,0 +1,81 @@
+package tgb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OffsetStore stores offset of the next update to receive by [Poller],
+// so polling resumes from the right place after restart.
+type OffsetStore interface {
+	// Load returns stored offset, 0 if it's not stored yet.
+	Load(ctx context.Context) (int, error)
+
+	// Save stores offset.
+	Save(ctx context.Context, offset int) error
+}
+
+// OffsetStoreFile stores offset in the file.
+type OffsetStoreFile struct {
+	path string
+}
+
+var _ OffsetStore = (*OffsetStoreFile)(nil)
+
+// NewOffsetStoreFile creates OffsetStore which keeps offset in the file by path.
+// File is synced and replaced atomically on each save.
+func NewOffsetStoreFile(path string) *OffsetStoreFile {
+	return &OffsetStoreFile{path: path}
+}
+
+// Load implements [OffsetStore].
+func (store *OffsetStoreFile) Load(ctx context.Context) (int, error) {
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("read offset file: %w", err)
+	}
+
+	offset, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("parse offset: %w", err)
+	}
+
+	return offset, nil
+}
+
+// Save implements [OffsetStore].
+func (store *OffsetStoreFile) Save(ctx context.Context, offset int) error {
+	tmp := store.path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("create offset file: %w", err)
+	}
+
+	if _, err := file.WriteString(strconv.Itoa(offset)); err != nil {
+		file.Close()
+		return fmt.Errorf("write offset file: %w", err)
+	}
+
+	// without sync, crash after rename can leave empty or previous offset
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("sync offset file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close offset file: %w", err)
+	}
+
+	if err := os.Rename(tmp, store.path); err != nil {
+		return fmt.Errorf("rename offset file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package tgb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffsetStoreFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "offset")
+
+	store := NewOffsetStoreFile(path)
+
+	offset, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, offset, "missing file should be loaded as zero")
+
+	require.NoError(t, store.Save(ctx, 42))
+
+	offset, err = NewOffsetStoreFile(path).Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 42, offset)
+
+	require.NoError(t, os.WriteFile(path, []byte("abc"), 0o600))
+
+	_, err = store.Load(ctx)
+	require.Error(t, err)
+}
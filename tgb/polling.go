@@ -20,6 +20,17 @@ type Poller struct {
 	retryAfter     time.Duration
 	limit          int
 	allowedUpdates []tg.UpdateType
+	allowedFrom    AllowedUpdatesSource
+	tracer         tg.Tracer
+	dispatcher     *Dispatcher
+	atLeastOnce    bool
+	offsetStore    OffsetStore
+
+	onConflict        func(ctx context.Context, err error, conflicts int)
+	conflictThreshold int
+	lockFile          string
+
+	queue UpdateQueue
 
 	wg sync.WaitGroup
 }
@@ -41,6 +52,7 @@ func WithPollerTimeout(timeout time.Duration) PollerOption {
 }
 
 // WithPollerRetryAfter sets the retry after for polling.
+// If getUpdates fails with [tg.CircuitOpenError], poller waits until the circuit becomes half-open, if it's longer.
 func WithPollerRetryAfter(retryAfter time.Duration) PollerOption {
 	return func(poller *Poller) {
 		poller.retryAfter = retryAfter
@@ -61,6 +73,15 @@ func WithPollerAllowedUpdates(allowedUpdates ...tg.UpdateType) PollerOption {
 	}
 }
 
+// WithPollerAllowedUpdatesFrom sets the allowed updates from source, e.g. [Router.AllowedUpdates].
+// Source is called when polling is started, so handlers registered after NewPoller are taken into account.
+// Overrides [WithPollerAllowedUpdates].
+func WithPollerAllowedUpdatesFrom(source AllowedUpdatesSource) PollerOption {
+	return func(poller *Poller) {
+		poller.allowedFrom = source
+	}
+}
+
 // WithPollerLogger sets the logger for the poller.
 func WithPollerLogger(logger Logger) PollerOption {
 	return func(poller *Poller) {
@@ -68,6 +89,102 @@ func WithPollerLogger(logger Logger) PollerOption {
 	}
 }
 
+// WithPollerTracer sets the tracer for the poller.
+// Each update is handled in span named "tgb.Poller.update",
+// so requests made by handler with [tg.NewInterceptorTracing] are its children.
+func WithPollerTracer(tracer tg.Tracer) PollerOption {
+	return func(poller *Poller) {
+		poller.tracer = tracer
+	}
+}
+
+// WithPollerDispatcher sets the dispatcher of updates.
+// By default each update is handled in its own goroutine, so updates from the same chat can be handled out of order.
+// With dispatcher, next batch of updates is fetched after all updates of the previous one are dispatched.
+func WithPollerDispatcher(dispatcher *Dispatcher) PollerOption {
+	return func(poller *Poller) {
+		poller.dispatcher = dispatcher
+	}
+}
+
+// WithPollerAtLeastOnce makes poller commit offset only after all handlers of the batch are done,
+// so updates are not lost if the process crashes while handling them.
+// Next batch is fetched after the previous one is handled.
+// Updates are committed even if handler returns error, but not if polling is stopped while handling,
+// so they are received again after restart (handlers should be idempotent).
+// On shutdown the committed offset is acknowledged with the final getUpdates call.
+func WithPollerAtLeastOnce() PollerOption {
+	return func(poller *Poller) {
+		poller.atLeastOnce = true
+	}
+}
+
+// WithPollerOffsetStore sets the store of offset, so polling resumes from the committed offset after restart.
+// See [OffsetStoreFile] for file based implementation.
+func WithPollerOffsetStore(store OffsetStore) PollerOption {
+	return func(poller *Poller) {
+		poller.offsetStore = store
+	}
+}
+
+// WithPollerQueue makes poller enqueue updates instead of handling them,
+// use [UpdateConsumer] to handle updates from the queue.
+// Offset is advanced after all updates of the batch are enqueued.
+func WithPollerQueue(queue UpdateQueue) PollerOption {
+	return func(poller *Poller) {
+		poller.queue = queue
+	}
+}
+
+// WithPollerOnConflict sets the callback called when getUpdates fails with [tg.ErrConflict],
+// which usually means another instance polls updates with the same token.
+// conflicts is the number of conflicts in a row.
+func WithPollerOnConflict(fn func(ctx context.Context, err error, conflicts int)) PollerOption {
+	return func(poller *Poller) {
+		poller.onConflict = fn
+	}
+}
+
+// WithPollerConflictThreshold makes [Poller.Run] return [PollerConflictError]
+// after threshold conflicts in a row, instead of retrying forever.
+// Zero (default) means no limit.
+func WithPollerConflictThreshold(threshold int) PollerOption {
+	return func(poller *Poller) {
+		poller.conflictThreshold = threshold
+	}
+}
+
+// WithPollerLockFile makes [Poller.Run] acquire the lock file by path before polling,
+// so only one process on the host polls updates of the bot.
+// If lock is held by another process, Run returns [ErrPollerLocked].
+//
+// On Linux, macOS and BSD lock is released by OS when process exits.
+// On other platforms the file is created exclusively and removed on shutdown,
+// so it should be removed manually after crash.
+func WithPollerLockFile(path string) PollerOption {
+	return func(poller *Poller) {
+		poller.lockFile = path
+	}
+}
+
+// PollerConflictError is returned by [Poller.Run] when getUpdates fails with [tg.ErrConflict]
+// more times in a row than allowed by [WithPollerConflictThreshold].
+type PollerConflictError struct {
+	// Conflicts is the number of conflicts in a row.
+	Conflicts int
+
+	// Err is the last error returned by getUpdates.
+	Err error
+}
+
+func (err *PollerConflictError) Error() string {
+	return fmt.Sprintf("%d conflicts in a row, is another instance running? last error: %v", err.Conflicts, err.Err)
+}
+
+func (err *PollerConflictError) Unwrap() error {
+	return err.Err
+}
+
 const defaultPollerLimit = 100
 
 func NewPoller(handler Handler, client *tg.Client, opts ...PollerOption) *Poller {
@@ -92,7 +209,7 @@ func NewPoller(handler Handler, client *tg.Client, opts ...PollerOption) *Poller
 
 func (poller *Poller) log(format string, args ...any) {
 	if poller.logger != nil {
-		poller.logger.Printf("tgb.Poller: "+format, args...)
+		poller.logger.Printf("tgb.Poller: "+format, redactLogArgs(poller.client, args)...)
 	}
 }
 
@@ -114,40 +231,92 @@ func (poller *Poller) removeWebhookIfSet(ctx context.Context) error {
 
 func (poller *Poller) processUpdates(ctx context.Context, updates []tg.Update) {
 	for i := range updates {
+		update := &Update{
+			Update: &updates[i],
+			Client: poller.client,
+		}
+
 		poller.wg.Add(1)
 
-		go func(i int) {
-			defer poller.wg.Done()
+		if poller.dispatcher == nil {
+			go poller.handleUpdate(ctx, update)
+			continue
+		}
 
-			if poller.handlerTimeout > 0 {
-				var cancel context.CancelFunc
-				ctx, cancel = context.WithTimeout(ctx, poller.handlerTimeout)
-				defer cancel()
-			}
+		err := poller.dispatcher.Dispatch(ctx, update, func() {
+			poller.handleUpdate(ctx, update)
+		})
+		if err != nil {
+			poller.wg.Done()
+			poller.log("update %d is not dispatched: %v", update.ID, err)
+		}
+	}
+}
 
-			update := &updates[i]
+// enqueueUpdates adds updates to the queue, stops on first error.
+func (poller *Poller) enqueueUpdates(ctx context.Context, updates []tg.Update) error {
+	for i := range updates {
+		if err := poller.queue.Enqueue(ctx, &updates[i]); err != nil {
+			return fmt.Errorf("enqueue update %d: %w", updates[i].ID, err)
+		}
+	}
 
-			err := poller.handler.Handle(ctx, &Update{
-				Update: update,
-				Client: poller.client,
-			})
-			if err != nil {
-				poller.log("error handling update: %v", err)
-			}
-		}(i)
+	return nil
+}
+
+func (poller *Poller) handleUpdate(ctx context.Context, update *Update) {
+	defer poller.wg.Done()
+
+	if poller.handlerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, poller.handlerTimeout)
+		defer cancel()
+	}
+
+	if err := handleTraced(ctx, poller.tracer, "tgb.Poller.update", poller.handler, update); err != nil {
+		poller.log("error handling update: %v", err)
 	}
 }
 
 func (poller *Poller) Run(ctx context.Context) error {
+	if poller.lockFile != "" {
+		unlock, err := lockFile(poller.lockFile)
+		if err != nil {
+			return fmt.Errorf("lock file: %w", err)
+		}
+
+		defer func() {
+			if err := unlock(); err != nil {
+				poller.log("error unlocking file: %v", err)
+			}
+		}()
+	}
+
+	if poller.allowedFrom != nil {
+		poller.allowedUpdates = poller.allowedFrom.AllowedUpdates()
+	}
+
 	if err := poller.removeWebhookIfSet(ctx); err != nil {
 		return fmt.Errorf("remove webhook if set: %w", err)
 	}
 
-	var offset int
+	var offset, acked, conflicts int
+
+	if poller.offsetStore != nil {
+		var err error
+		if offset, err = poller.offsetStore.Load(ctx); err != nil {
+			return fmt.Errorf("load offset: %w", err)
+		}
+		acked = offset
+	}
 
 	defer func() {
 		poller.log("shutdown...")
 		poller.wg.Wait()
+
+		if poller.atLeastOnce && offset > acked {
+			poller.ack(ctx, offset)
+		}
 	}()
 
 	for {
@@ -159,21 +328,49 @@ func (poller *Poller) Run(ctx context.Context) error {
 			call := poller.client.
 				GetUpdates().
 				Offset(offset).
-				Timeout(int(poller.timeout.Seconds())).
-				AllowedUpdates(poller.allowedUpdates)
+				Timeout(int(poller.timeout.Seconds()))
+
+			// nil keeps allowed updates of previous call
+			if poller.allowedUpdates != nil {
+				call = call.AllowedUpdates(poller.allowedUpdates)
+			}
 
 			if poller.limit != defaultPollerLimit {
 				call = call.Limit(poller.limit)
 			}
 
 			updates, err := call.Do(ctx)
+			if err == nil {
+				acked = offset
+				conflicts = 0
+			}
 
 			if err != nil && !errors.Is(err, context.Canceled) {
-				poller.log("error '%s' when getting updates, retrying in %v...", err, poller.retryAfter)
+				if errors.Is(err, tg.ErrConflict) {
+					conflicts++
+
+					if poller.onConflict != nil {
+						poller.onConflict(ctx, err, conflicts)
+					}
+
+					if poller.conflictThreshold > 0 && conflicts >= poller.conflictThreshold {
+						return &PollerConflictError{Conflicts: conflicts, Err: err}
+					}
+				}
 
-				if poller.retryAfter > 0 {
+				retryAfter := poller.retryAfter
+
+				// wait until circuit breaker lets requests through instead of failing fast in loop
+				var circuitErr *tg.CircuitOpenError
+				if errors.As(err, &circuitErr) && circuitErr.RetryAfter > retryAfter {
+					retryAfter = circuitErr.RetryAfter
+				}
+
+				poller.log("error '%s' when getting updates, retrying in %v...", err, retryAfter)
+
+				if retryAfter > 0 {
 					select {
-					case <-time.After(poller.retryAfter):
+					case <-time.After(retryAfter):
 					case <-ctx.Done():
 						return nil
 					}
@@ -181,10 +378,74 @@ func (poller *Poller) Run(ctx context.Context) error {
 				continue
 			}
 
-			if len(updates) > 0 {
-				offset = updates[len(updates)-1].ID + 1
+			if len(updates) == 0 {
+				continue
+			}
+
+			next := updates[len(updates)-1].ID + 1
+
+			if poller.queue != nil {
+				if err := poller.enqueueUpdates(ctx, updates); err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+
+					poller.log("error enqueueing updates, retrying in %v: %v", poller.retryAfter, err)
+
+					select {
+					case <-time.After(poller.retryAfter):
+					case <-ctx.Done():
+						return nil
+					}
+					continue
+				}
+			} else if poller.atLeastOnce {
+				poller.processUpdates(ctx, updates)
+				poller.wg.Wait()
+
+				// handlers could be interrupted, so updates should be received again
+				if ctx.Err() != nil {
+					return nil
+				}
+			} else if poller.dispatcher != nil {
+				// dispatcher preserves order of updates, so batches are dispatched one by one
+				poller.processUpdates(ctx, updates)
+			} else {
 				go poller.processUpdates(ctx, updates)
 			}
+
+			offset = next
+
+			if poller.offsetStore != nil {
+				if err := poller.offsetStore.Save(ctx, offset); err != nil {
+					poller.log("error saving offset %d: %v", offset, err)
+				}
+			}
 		}
 	}
 }
+
+// pollerAckTimeout is the timeout of final getUpdates call acknowledging the committed offset.
+const pollerAckTimeout = 5 * time.Second
+
+// ack acknowledges updates before offset, so they are not delivered again.
+func (poller *Poller) ack(ctx context.Context, offset int) {
+	// polling context is canceled on shutdown
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), pollerAckTimeout)
+	defer cancel()
+
+	call := poller.client.
+		GetUpdates().
+		Offset(offset).
+		Timeout(0).
+		Limit(1)
+
+	if poller.allowedUpdates != nil {
+		call = call.AllowedUpdates(poller.allowedUpdates)
+	}
+
+	err := call.DoVoid(ctx)
+	if err != nil {
+		poller.log("error acknowledging offset %d: %v", offset, err)
+	}
+}
@@ -0,0 +1,6 @@
+package tgb
+
+import "errors"
+
+// ErrPollerLocked is returned by [Poller.Run] if lock file set by [WithPollerLockFile] is held by another process.
+var ErrPollerLocked = errors.New("lock file is held by another process")
@@ -0,0 +1,32 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package tgb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// lockFile creates the file by path exclusively and removes it on unlock.
+// File is left if process crashes, so it should be removed manually.
+func lockFile(path string) (unlock func() error, err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return nil, ErrPollerLocked
+	} else if err != nil {
+		return nil, fmt.Errorf("create: %w", err)
+	}
+
+	_, _ = file.WriteString(strconv.Itoa(os.Getpid()))
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("close: %w", err)
+	}
+
+	return func() error {
+		return os.Remove(path)
+	}, nil
+}
@@ -2,10 +2,14 @@ package tgb
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -155,4 +159,391 @@ func TestPolling_log(t *testing.T) {
 
 		logger.AssertExpectations(t)
 	})
+
+	t.Run("Redact", func(t *testing.T) {
+		logger := &loggerMock{}
+
+		poller := NewPoller(
+			HandlerFunc(func(ctx context.Context, update *Update) error { return nil }),
+			tg.New("1234:secret"),
+			WithPollerLogger(logger),
+		)
+
+		logger.On("Printf", "tgb.Poller: %s: %v (%d)", mock.MatchedBy(func(args []any) bool {
+			return len(args) == 3 &&
+				args[0] == "/bot<redacted>/getUpdates" &&
+				args[1].(error).Error() == "get /bot<redacted>/getUpdates: failed" &&
+				args[2] == 1
+		})).Return()
+
+		poller.log("%s: %v (%d)", "/bot1234:secret/getUpdates", errors.New("get /bot1234:secret/getUpdates: failed"), 1)
+
+		logger.AssertExpectations(t)
+	})
+}
+
+func TestPoller_Conflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot1234:secret/getWebhookInfo":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"url":""}}`))
+		case "/bot1234:secret/getUpdates":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":409,"description":"Conflict: terminated by other getUpdates request; make sure that only one bot instance is running"}`))
+		default:
+			t.Errorf("unexpected call '%s'", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var conflicts []int
+
+	err := NewPoller(
+		HandlerFunc(func(ctx context.Context, update *Update) error {
+			t.Error("should not be called")
+			return nil
+		}),
+		tg.New("1234:secret", tg.WithClientServerURL(server.URL), tg.WithClientDoer(server.Client())),
+		WithPollerRetryAfter(0),
+		WithPollerOnConflict(func(ctx context.Context, err error, n int) {
+			assert.ErrorIs(t, err, tg.ErrConflict)
+			conflicts = append(conflicts, n)
+		}),
+		WithPollerConflictThreshold(3),
+	).Run(context.Background())
+
+	var conflictErr *PollerConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, 3, conflictErr.Conflicts)
+	assert.ErrorIs(t, err, tg.ErrConflict)
+	assert.Equal(t, []int{1, 2, 3}, conflicts)
+}
+
+func TestPoller_LockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "poller.lock")
+
+	unlock, err := lockFile(path)
+	require.NoError(t, err)
+
+	_, err = lockFile(path)
+	require.ErrorIs(t, err, ErrPollerLocked)
+
+	err = NewPoller(
+		HandlerFunc(func(ctx context.Context, update *Update) error {
+			return nil
+		}),
+		&tg.Client{},
+		WithPollerLockFile(path),
+	).Run(context.Background())
+	require.ErrorIs(t, err, ErrPollerLocked, "poller should not start if lock is held")
+
+	require.NoError(t, unlock())
+
+	unlock, err = lockFile(path)
+	require.NoError(t, err, "lock should be acquired after unlock")
+	require.NoError(t, unlock())
+}
+
+func TestPoller_AllowedUpdatesFrom(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot1234:secret/getWebhookInfo":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"url":""}}`))
+		case "/bot1234:secret/getUpdates":
+			assert.Equal(t, `["message","chat_member"]`, r.FormValue("allowed_updates"))
+			cancel()
+			_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+		default:
+			t.Errorf("unexpected call '%s'", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	router := NewRouter()
+
+	poller := NewPoller(router,
+		tg.New("1234:secret", tg.WithClientServerURL(server.URL), tg.WithClientDoer(server.Client())),
+		WithPollerAllowedUpdatesFrom(router),
+	)
+
+	// handlers registered after poller is created are taken into account
+	router.
+		ChatMember(func(ctx context.Context, cmu *ChatMemberUpdatedUpdate) error { return nil }).
+		Message(func(ctx context.Context, mu *MessageUpdate) error { return nil })
+
+	require.NoError(t, poller.Run(ctx))
+
+	t.Run("NoHandlers", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/bot1234:secret/getWebhookInfo":
+				_, _ = w.Write([]byte(`{"ok":true,"result":{"url":""}}`))
+			case "/bot1234:secret/getUpdates":
+				require.NoError(t, r.ParseForm())
+				assert.NotContains(t, r.Form, "allowed_updates", "allowed updates should not be changed")
+				cancel()
+				_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+			default:
+				t.Errorf("unexpected call '%s'", r.URL.Path)
+			}
+		}))
+		defer server.Close()
+
+		router := NewRouter()
+
+		err := NewPoller(router,
+			tg.New("1234:secret", tg.WithClientServerURL(server.URL), tg.WithClientDoer(server.Client())),
+			WithPollerAllowedUpdatesFrom(router),
+		).Run(ctx)
+		require.NoError(t, err)
+	})
+}
+
+func TestPoller_Dispatcher(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot1234:secret/getWebhookInfo":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"url":""}}`))
+		case "/bot1234:secret/getUpdates":
+			if calls.Add(1) == 1 {
+				_, _ = w.Write([]byte(`{"ok":true,"result":[
+					{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":1,"type":"private"}}},
+					{"update_id":2,"message":{"message_id":2,"date":1,"chat":{"id":1,"type":"private"}}},
+					{"update_id":3,"message":{"message_id":3,"date":1,"chat":{"id":1,"type":"private"}}}
+				]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+		default:
+			t.Errorf("unexpected call '%s'", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		lock    sync.Mutex
+		handled []int
+	)
+
+	err := NewPoller(
+		HandlerFunc(func(ctx context.Context, update *Update) error {
+			// first update is the slowest one
+			time.Sleep(time.Duration(4-update.ID) * time.Millisecond)
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			handled = append(handled, update.ID)
+			if len(handled) == 3 {
+				cancel()
+			}
+
+			return nil
+		}),
+		tg.New("1234:secret", tg.WithClientServerURL(server.URL), tg.WithClientDoer(server.Client())),
+		WithPollerDispatcher(NewDispatcher()),
+		WithPollerTimeout(0),
+	).Run(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2, 3}, handled)
+}
+
+type offsetStoreMemory struct {
+	lock   sync.Mutex
+	offset int
+	saved  []int
+}
+
+func (store *offsetStoreMemory) Load(ctx context.Context) (int, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	return store.offset, nil
+}
+
+func (store *offsetStoreMemory) Save(ctx context.Context, offset int) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.offset = offset
+	store.saved = append(store.saved, offset)
+
+	return nil
+}
+
+type pollerTestCall struct {
+	Offset  string
+	Timeout string
+}
+
+// newPollerTestServer returns server responding to getUpdates with batches one by one,
+// onEmpty is called with request when all batches are sent.
+func newPollerTestServer(t *testing.T, batches []string, onEmpty func(r *http.Request)) (*httptest.Server, func() []pollerTestCall) {
+	t.Helper()
+
+	var (
+		lock  sync.Mutex
+		calls []pollerTestCall
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot1234:secret/getWebhookInfo":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"url":""}}`))
+		case "/bot1234:secret/getUpdates":
+			if !assert.NoError(t, r.ParseForm()) {
+				return
+			}
+
+			lock.Lock()
+			calls = append(calls, pollerTestCall{Offset: r.PostForm.Get("offset"), Timeout: r.PostForm.Get("timeout")})
+			n := len(calls)
+			lock.Unlock()
+
+			if n <= len(batches) {
+				_, _ = w.Write([]byte(`{"ok":true,"result":` + batches[n-1] + `}`))
+				return
+			}
+
+			if onEmpty != nil {
+				onEmpty(r)
+			}
+			_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+		default:
+			t.Errorf("unexpected call '%s'", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []pollerTestCall {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return calls
+	}
+}
+
+func TestPoller_AtLeastOnce(t *testing.T) {
+	t.Run("Commit", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		server, calls := newPollerTestServer(t, []string{
+			`[{"update_id":10},{"update_id":11}]`,
+			`[{"update_id":12}]`,
+		}, func(r *http.Request) {
+			// stop polling while waiting for updates
+			if r.PostForm.Get("timeout") != "0" {
+				cancel()
+				<-r.Context().Done()
+			}
+		})
+
+		store := &offsetStoreMemory{}
+
+		var handled []int
+
+		err := NewPoller(
+			HandlerFunc(func(ctx context.Context, update *Update) error {
+				handled = append(handled, update.ID)
+
+				if update.ID == 12 {
+					return assert.AnError
+				}
+
+				return nil
+			}),
+			tg.New("1234:secret", tg.WithClientServerURL(server.URL), tg.WithClientDoer(server.Client())),
+			WithPollerAtLeastOnce(),
+			WithPollerOffsetStore(store),
+			WithPollerDispatcher(NewDispatcher(WithDispatcherWorkers(1))),
+			WithPollerTimeout(time.Second),
+		).Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{10, 11, 12}, handled)
+		assert.Equal(t, []int{12, 13}, store.saved, "failed updates should be committed too")
+		assert.Equal(t, []pollerTestCall{
+			{Offset: "0", Timeout: "1"},
+			{Offset: "12", Timeout: "1"},
+			{Offset: "13", Timeout: "1"},
+			{Offset: "13", Timeout: "0"},
+		}, calls(), "committed offset should be acknowledged on shutdown")
+	})
+
+	t.Run("Interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		server, calls := newPollerTestServer(t, []string{
+			`[{"update_id":10},{"update_id":11}]`,
+		}, nil)
+
+		store := &offsetStoreMemory{offset: 10}
+
+		var handled []int
+
+		err := NewPoller(
+			HandlerFunc(func(ctx context.Context, update *Update) error {
+				handled = append(handled, update.ID)
+
+				// stop polling while handling the batch
+				if update.ID == 11 {
+					cancel()
+				}
+
+				return nil
+			}),
+			tg.New("1234:secret", tg.WithClientServerURL(server.URL), tg.WithClientDoer(server.Client())),
+			WithPollerAtLeastOnce(),
+			WithPollerOffsetStore(store),
+			WithPollerDispatcher(NewDispatcher(WithDispatcherWorkers(1))),
+			WithPollerTimeout(time.Second),
+		).Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{10, 11}, handled)
+		assert.Empty(t, store.saved, "interrupted batch should not be committed")
+		assert.Equal(t, []pollerTestCall{
+			{Offset: "10", Timeout: "1"},
+		}, calls(), "offset should be loaded from store and nothing acknowledged")
+	})
+}
+
+func TestPoller_Queue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue := NewUpdateQueueMemory()
+
+	server, _ := newPollerTestServer(t, []string{
+		`[{"update_id":10},{"update_id":11}]`,
+	}, func(r *http.Request) {
+		cancel()
+	})
+
+	err := NewPoller(
+		HandlerFunc(func(ctx context.Context, update *Update) error {
+			t.Error("should not be called")
+			return nil
+		}),
+		tg.New("1234:secret", tg.WithClientServerURL(server.URL), tg.WithClientDoer(server.Client())),
+		WithPollerQueue(queue),
+		WithPollerTimeout(time.Second),
+	).Run(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{10, 11}, dequeueIDs(t, queue, 2))
 }
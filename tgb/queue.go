@@ -0,0 +1,449 @@
+package tgb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	tg "github.com/mr-linch/go-tg"
+)
+
+// UpdateQueue decouples receiving updates from handling them.
+// Receivers ([WithPollerQueue], [WithWebhookQueue]) enqueue updates,
+// and [UpdateConsumer] dequeues and handles them.
+type UpdateQueue interface {
+	// Enqueue adds update to the queue.
+	// Updates already in the queue (e.g. delivered by Telegram again) are ignored.
+	Enqueue(ctx context.Context, update *tg.Update) error
+
+	// Dequeue returns the next update, waiting for it until ctx is done.
+	// Update is kept in the queue until it's acknowledged.
+	Dequeue(ctx context.Context) (*tg.Update, error)
+
+	// Ack removes the dequeued update from the queue.
+	Ack(ctx context.Context, id int) error
+
+	// Nack returns the dequeued update to the front of the queue, so it's dequeued again.
+	Nack(ctx context.Context, id int) error
+}
+
+// UpdateQueueMemory is in-memory [UpdateQueue].
+// Updates are lost on restart.
+type UpdateQueueMemory struct {
+	lock     sync.Mutex
+	pending  []*tg.Update
+	inflight map[int]*tg.Update
+	notify   chan struct{}
+}
+
+var _ UpdateQueue = (*UpdateQueueMemory)(nil)
+
+// NewUpdateQueueMemory creates in-memory [UpdateQueue].
+func NewUpdateQueueMemory() *UpdateQueueMemory {
+	return &UpdateQueueMemory{
+		inflight: make(map[int]*tg.Update),
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// Len returns number of updates in the queue, including dequeued but not acknowledged.
+func (queue *UpdateQueueMemory) Len() int {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	return len(queue.pending) + len(queue.inflight)
+}
+
+// has reports whether update with id is in the queue, lock should be held.
+func (queue *UpdateQueueMemory) has(id int) bool {
+	if _, ok := queue.inflight[id]; ok {
+		return true
+	}
+
+	for _, update := range queue.pending {
+		if update.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// push adds update to the queue, lock should be held.
+func (queue *UpdateQueueMemory) push(update *tg.Update) {
+	queue.pending = append(queue.pending, update)
+
+	select {
+	case queue.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Enqueue implements [UpdateQueue].
+func (queue *UpdateQueueMemory) Enqueue(ctx context.Context, update *tg.Update) error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	if !queue.has(update.ID) {
+		queue.push(update)
+	}
+
+	return nil
+}
+
+// Dequeue implements [UpdateQueue].
+func (queue *UpdateQueueMemory) Dequeue(ctx context.Context) (*tg.Update, error) {
+	for {
+		queue.lock.Lock()
+		if len(queue.pending) > 0 {
+			update := queue.pending[0]
+			queue.pending[0] = nil
+			queue.pending = queue.pending[1:]
+			queue.inflight[update.ID] = update
+
+			// wake up next waiter, if any
+			if len(queue.pending) > 0 {
+				select {
+				case queue.notify <- struct{}{}:
+				default:
+				}
+			}
+
+			queue.lock.Unlock()
+			return update, nil
+		}
+		queue.lock.Unlock()
+
+		select {
+		case <-queue.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Ack implements [UpdateQueue].
+func (queue *UpdateQueueMemory) Ack(ctx context.Context, id int) error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	delete(queue.inflight, id)
+
+	return nil
+}
+
+// Nack implements [UpdateQueue].
+func (queue *UpdateQueueMemory) Nack(ctx context.Context, id int) error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	update, ok := queue.inflight[id]
+	if !ok {
+		return nil
+	}
+
+	delete(queue.inflight, id)
+	queue.pending = append([]*tg.Update{update}, queue.pending...)
+
+	select {
+	case queue.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// live returns updates in the queue, dequeued first.
+func (queue *UpdateQueueMemory) live() []*tg.Update {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	updates := make([]*tg.Update, 0, len(queue.inflight)+len(queue.pending))
+	for _, update := range queue.inflight {
+		updates = append(updates, update)
+	}
+
+	// updates are dequeued in order of ids, usually
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].ID < updates[j].ID
+	})
+
+	return append(updates, queue.pending...)
+}
+
+// UpdateQueueFile is [UpdateQueue] backed by append-only file, so updates survive restarts.
+// Updates dequeued but not acknowledged before restart are dequeued again.
+//
+// Each enqueued update and acknowledgement is appended to the file as JSON line and synced to disk.
+// The file is compacted on open, when obsolete records outnumber updates in the queue
+// (see [WithUpdateQueueFileCompactAfter]) and truncated when all updates are acknowledged.
+type UpdateQueueFile struct {
+	path         string
+	compactAfter int
+
+	lock    sync.Mutex
+	file    *os.File
+	records int
+
+	memory *UpdateQueueMemory
+}
+
+// UpdateQueueFileOption is an option for OpenUpdateQueueFile.
+type UpdateQueueFileOption func(*UpdateQueueFile)
+
+// WithUpdateQueueFileCompactAfter sets min number of obsolete records
+// (of acknowledged updates and acknowledgements) to compact the file.
+// Compaction is also deferred until obsolete records outnumber updates in the queue.
+// Default is 1000.
+func WithUpdateQueueFileCompactAfter(records int) UpdateQueueFileOption {
+	return func(queue *UpdateQueueFile) {
+		queue.compactAfter = records
+	}
+}
+
+const defaultUpdateQueueFileCompactAfter = 1000
+
+var _ UpdateQueue = (*UpdateQueueFile)(nil)
+
+// updateQueueRecord is the line of [UpdateQueueFile].
+type updateQueueRecord struct {
+	Update *tg.Update `json:"update,omitempty"`
+	Ack    int        `json:"ack,omitempty"`
+}
+
+// OpenUpdateQueueFile opens [UpdateQueueFile] by path, creating it if not exists.
+// Updates left in the file are restored in order of enqueue.
+func OpenUpdateQueueFile(path string, opts ...UpdateQueueFileOption) (*UpdateQueueFile, error) {
+	queue := &UpdateQueueFile{
+		path:         path,
+		compactAfter: defaultUpdateQueueFileCompactAfter,
+		memory:       NewUpdateQueueMemory(),
+	}
+
+	for _, opt := range opts {
+		opt(queue)
+	}
+
+	if err := queue.restore(); err != nil {
+		return nil, err
+	}
+
+	if err := queue.compact(); err != nil {
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+// restore reads pending updates from the file.
+func (queue *UpdateQueueFile) restore() error {
+	file, err := os.Open(queue.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("open queue file: %w", err)
+	}
+	defer file.Close()
+
+	acked := make(map[int]bool)
+
+	var updates []*tg.Update
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	for scanner.Scan() {
+		var record updateQueueRecord
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// line could be written partially on crash, records after it are still valid
+			continue
+		}
+
+		if record.Update != nil {
+			updates = append(updates, record.Update)
+			delete(acked, record.Update.ID)
+		} else {
+			acked[record.Ack] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read queue file: %w", err)
+	}
+
+	for _, update := range updates {
+		if !acked[update.ID] && !queue.memory.has(update.ID) {
+			queue.memory.push(update)
+		}
+	}
+
+	return nil
+}
+
+// compact rewrites the file with updates in the queue only and opens it for appending, lock should be held.
+// Dequeued but not acknowledged updates are kept, so they are restored after restart.
+func (queue *UpdateQueueFile) compact() error {
+	tmp := queue.path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("create queue file: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	updates := queue.memory.live()
+
+	for _, update := range updates {
+		if err := encoder.Encode(updateQueueRecord{Update: update}); err != nil {
+			file.Close()
+			return fmt.Errorf("write queue file: %w", err)
+		}
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("sync queue file: %w", err)
+	}
+
+	if err := os.Rename(tmp, queue.path); err != nil {
+		file.Close()
+		return fmt.Errorf("rename queue file: %w", err)
+	}
+
+	if queue.file != nil {
+		_ = queue.file.Close()
+	}
+
+	queue.file = file
+	queue.records = len(updates)
+
+	return nil
+}
+
+// write appends record to the file, lock should be held.
+// On failure the file is truncated back, so partial record is not followed by next ones.
+func (queue *UpdateQueueFile) write(record updateQueueRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+
+	size, err := queue.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("seek queue file: %w", err)
+	}
+
+	if _, err := queue.file.Write(append(data, '\n')); err != nil {
+		return errors.Join(fmt.Errorf("write queue file: %w", err), queue.truncate(size))
+	}
+
+	if err := queue.file.Sync(); err != nil {
+		return errors.Join(fmt.Errorf("sync queue file: %w", err), queue.truncate(size))
+	}
+
+	queue.records++
+
+	return nil
+}
+
+// truncate cuts the file to size and moves write offset to the end, lock should be held.
+func (queue *UpdateQueueFile) truncate(size int64) error {
+	if err := queue.file.Truncate(size); err != nil {
+		return fmt.Errorf("truncate queue file: %w", err)
+	}
+
+	if _, err := queue.file.Seek(size, io.SeekStart); err != nil {
+		return fmt.Errorf("seek queue file: %w", err)
+	}
+
+	return nil
+}
+
+// Len returns number of updates in the queue, including dequeued but not acknowledged.
+func (queue *UpdateQueueFile) Len() int {
+	return queue.memory.Len()
+}
+
+// Enqueue implements [UpdateQueue].
+// Update is synced to disk when Enqueue returns.
+func (queue *UpdateQueueFile) Enqueue(ctx context.Context, update *tg.Update) error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	queue.memory.lock.Lock()
+	defer queue.memory.lock.Unlock()
+
+	if queue.memory.has(update.ID) {
+		return nil
+	}
+
+	if err := queue.write(updateQueueRecord{Update: update}); err != nil {
+		return err
+	}
+
+	queue.memory.push(update)
+
+	return nil
+}
+
+// Dequeue implements [UpdateQueue].
+func (queue *UpdateQueueFile) Dequeue(ctx context.Context) (*tg.Update, error) {
+	return queue.memory.Dequeue(ctx)
+}
+
+// Ack implements [UpdateQueue].
+func (queue *UpdateQueueFile) Ack(ctx context.Context, id int) error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	if err := queue.write(updateQueueRecord{Ack: id}); err != nil {
+		return err
+	}
+
+	if err := queue.memory.Ack(ctx, id); err != nil {
+		return err
+	}
+
+	live := queue.memory.Len()
+
+	// all records are obsolete when queue is empty
+	if live == 0 {
+		if err := queue.truncate(0); err != nil {
+			return err
+		}
+
+		queue.records = 0
+
+		return nil
+	}
+
+	// queue is rarely empty under load, so the file is compacted to not grow without bound
+	if obsolete := queue.records - live; obsolete >= queue.compactAfter && obsolete > live {
+		if err := queue.compact(); err != nil {
+			return fmt.Errorf("compact: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Nack implements [UpdateQueue].
+// Nothing is written to the file, since dequeue is not persisted.
+func (queue *UpdateQueueFile) Nack(ctx context.Context, id int) error {
+	return queue.memory.Nack(ctx, id)
+}
+
+// Close closes the file.
+func (queue *UpdateQueueFile) Close() error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	return queue.file.Close()
+}
@@ -0,0 +1,230 @@
+package tgb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	tg "github.com/mr-linch/go-tg"
+)
+
+// UpdateConsumer handles updates from [UpdateQueue].
+// Failed updates are retried, and passed to dead letter callback when tries are exhausted.
+// Update is acknowledged after it's handled or passed to dead letter.
+// Updates interrupted by shutdown are returned to the queue,
+// so they are handled again by the next Run or after restart (handlers should be idempotent).
+type UpdateConsumer struct {
+	queue   UpdateQueue
+	handler Handler
+	client  *tg.Client
+
+	logger         Logger
+	tracer         tg.Tracer
+	dispatcher     *Dispatcher
+	handlerTimeout time.Duration
+
+	tries      int
+	backoff    tg.RetryBackoff
+	deadLetter func(ctx context.Context, update *tg.Update, err error)
+
+	timeAfter func(time.Duration) <-chan time.Time
+
+	wg sync.WaitGroup
+}
+
+// UpdateConsumerOption is an option for NewUpdateConsumer.
+type UpdateConsumerOption func(*UpdateConsumer)
+
+// WithUpdateConsumerTries sets max number of handler calls for each update.
+// Default is 3.
+func WithUpdateConsumerTries(tries int) UpdateConsumerOption {
+	return func(consumer *UpdateConsumer) {
+		consumer.tries = tries
+	}
+}
+
+// WithUpdateConsumerBackoff sets delay between handler calls.
+// Default is exponential from 1s to 1m, see [tg.RetryBackoffExponential].
+func WithUpdateConsumerBackoff(backoff tg.RetryBackoff) UpdateConsumerOption {
+	return func(consumer *UpdateConsumer) {
+		consumer.backoff = backoff
+	}
+}
+
+// WithUpdateConsumerDeadLetter sets callback called with update and the last error when tries are exhausted.
+// By default such updates are logged and dropped.
+func WithUpdateConsumerDeadLetter(fn func(ctx context.Context, update *tg.Update, err error)) UpdateConsumerOption {
+	return func(consumer *UpdateConsumer) {
+		consumer.deadLetter = fn
+	}
+}
+
+// WithUpdateConsumerDispatcher sets the dispatcher of updates.
+// By default updates are handled one by one.
+func WithUpdateConsumerDispatcher(dispatcher *Dispatcher) UpdateConsumerOption {
+	return func(consumer *UpdateConsumer) {
+		consumer.dispatcher = dispatcher
+	}
+}
+
+// WithUpdateConsumerHandlerTimeout sets the timeout of each handler call.
+func WithUpdateConsumerHandlerTimeout(timeout time.Duration) UpdateConsumerOption {
+	return func(consumer *UpdateConsumer) {
+		consumer.handlerTimeout = timeout
+	}
+}
+
+// WithUpdateConsumerLogger sets the logger for the consumer.
+func WithUpdateConsumerLogger(logger Logger) UpdateConsumerOption {
+	return func(consumer *UpdateConsumer) {
+		consumer.logger = logger
+	}
+}
+
+// WithUpdateConsumerTracer sets the tracer for the consumer.
+// Each handler call is made in span named "tgb.UpdateConsumer.update".
+func WithUpdateConsumerTracer(tracer tg.Tracer) UpdateConsumerOption {
+	return func(consumer *UpdateConsumer) {
+		consumer.tracer = tracer
+	}
+}
+
+// WithUpdateConsumerTimeAfter sets time.After implementation, useful for testing.
+func WithUpdateConsumerTimeAfter(timeAfter func(time.Duration) <-chan time.Time) UpdateConsumerOption {
+	return func(consumer *UpdateConsumer) {
+		consumer.timeAfter = timeAfter
+	}
+}
+
+const defaultUpdateConsumerTries = 3
+
+// NewUpdateConsumer creates consumer of updates from queue.
+func NewUpdateConsumer(queue UpdateQueue, handler Handler, client *tg.Client, opts ...UpdateConsumerOption) *UpdateConsumer {
+	consumer := &UpdateConsumer{
+		queue:   queue,
+		handler: handler,
+		client:  client,
+
+		tries:   defaultUpdateConsumerTries,
+		backoff: tg.RetryBackoffExponential(time.Second, time.Minute),
+
+		timeAfter: time.After,
+	}
+
+	for _, opt := range opts {
+		opt(consumer)
+	}
+
+	return consumer
+}
+
+func (consumer *UpdateConsumer) log(format string, args ...any) {
+	if consumer.logger != nil {
+		consumer.logger.Printf("tgb.UpdateConsumer: "+format, redactLogArgs(consumer.client, args)...)
+	}
+}
+
+// Run consumes updates until ctx is done.
+// Waits for handlers in progress before return.
+func (consumer *UpdateConsumer) Run(ctx context.Context) error {
+	defer consumer.wg.Wait()
+
+	for {
+		// interrupted update is returned to the queue, so it's available even if ctx is done
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		update, err := consumer.queue.Dequeue(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("dequeue: %w", err)
+		}
+
+		consumer.wg.Add(1)
+
+		if consumer.dispatcher == nil {
+			consumer.consume(ctx, update)
+			continue
+		}
+
+		err = consumer.dispatcher.Dispatch(ctx, &Update{Update: update, Client: consumer.client}, func() {
+			consumer.consume(ctx, update)
+		})
+		if err != nil {
+			consumer.nack(ctx, update)
+			consumer.wg.Done()
+			return nil
+		}
+	}
+}
+
+// consume handles the update with retries and acknowledges it.
+func (consumer *UpdateConsumer) consume(ctx context.Context, update *tg.Update) {
+	defer consumer.wg.Done()
+
+	var (
+		err   error
+		delay time.Duration
+	)
+
+	for attempt := 1; ; attempt++ {
+		if err = consumer.handle(ctx, update); err == nil {
+			break
+		}
+
+		if ctx.Err() != nil {
+			consumer.nack(ctx, update)
+			return
+		}
+
+		if attempt >= consumer.tries {
+			if consumer.deadLetter != nil {
+				consumer.deadLetter(ctx, update, err)
+			} else {
+				consumer.log("update %d is dropped after %d tries: %v", update.ID, attempt, err)
+			}
+			break
+		}
+
+		delay = consumer.backoff(attempt, delay)
+
+		consumer.log("error handling update %d, retrying in %v: %v", update.ID, delay, err)
+
+		select {
+		case <-consumer.timeAfter(delay):
+		case <-ctx.Done():
+			consumer.nack(ctx, update)
+			return
+		}
+	}
+
+	// acknowledge even if consumer is stopping, because update is handled already
+	if err := consumer.queue.Ack(context.WithoutCancel(ctx), update.ID); err != nil {
+		consumer.log("error acknowledging update %d: %v", update.ID, err)
+	}
+}
+
+// nack returns update interrupted by shutdown to the queue.
+func (consumer *UpdateConsumer) nack(ctx context.Context, update *tg.Update) {
+	if err := consumer.queue.Nack(context.WithoutCancel(ctx), update.ID); err != nil {
+		consumer.log("error returning update %d to queue: %v", update.ID, err)
+	}
+}
+
+func (consumer *UpdateConsumer) handle(ctx context.Context, update *tg.Update) error {
+	if consumer.handlerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, consumer.handlerTimeout)
+		defer cancel()
+	}
+
+	return handleTraced(ctx, consumer.tracer, "tgb.UpdateConsumer.update", consumer.handler, &Update{
+		Update: update,
+		Client: consumer.client,
+	})
+}
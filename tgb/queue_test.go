@@ -0,0 +1,265 @@
+package tgb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tg "github.com/mr-linch/go-tg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dequeueIDs(t *testing.T, queue UpdateQueue, n int) []int {
+	t.Helper()
+
+	ids := make([]int, 0, n)
+
+	for i := 0; i < n; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		update, err := queue.Dequeue(ctx)
+		cancel()
+		require.NoError(t, err)
+
+		ids = append(ids, update.ID)
+	}
+
+	return ids
+}
+
+func TestUpdateQueueMemory(t *testing.T) {
+	ctx := context.Background()
+	queue := NewUpdateQueueMemory()
+
+	for _, id := range []int{1, 2, 1, 3} {
+		require.NoError(t, queue.Enqueue(ctx, &tg.Update{ID: id}))
+	}
+
+	assert.Equal(t, 3, queue.Len(), "duplicates should be ignored")
+	assert.Equal(t, []int{1, 2}, dequeueIDs(t, queue, 2))
+
+	// in-flight update is still in the queue
+	require.NoError(t, queue.Enqueue(ctx, &tg.Update{ID: 1}))
+	assert.Equal(t, 3, queue.Len())
+
+	require.NoError(t, queue.Ack(ctx, 1))
+	require.NoError(t, queue.Ack(ctx, 2))
+	assert.Equal(t, 1, queue.Len())
+
+	assert.Equal(t, []int{3}, dequeueIDs(t, queue, 1))
+
+	require.NoError(t, queue.Enqueue(ctx, &tg.Update{ID: 4}))
+	require.NoError(t, queue.Nack(ctx, 3))
+	assert.Equal(t, []int{3, 4}, dequeueIDs(t, queue, 2), "returned update should be dequeued first")
+	require.NoError(t, queue.Ack(ctx, 3))
+	require.NoError(t, queue.Ack(ctx, 4))
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+
+	_, err := queue.Dequeue(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	t.Run("Wait", func(t *testing.T) {
+		queue := NewUpdateQueueMemory()
+
+		go func() {
+			time.Sleep(time.Millisecond)
+			_ = queue.Enqueue(context.Background(), &tg.Update{ID: 1})
+		}()
+
+		assert.Equal(t, []int{1}, dequeueIDs(t, queue, 1))
+	})
+}
+
+func TestUpdateQueueFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+
+	queue, err := OpenUpdateQueueFile(path)
+	require.NoError(t, err)
+
+	for _, id := range []int{1, 2, 3, 2} {
+		require.NoError(t, queue.Enqueue(ctx, &tg.Update{ID: id, Message: &tg.Message{ID: id}}))
+	}
+
+	assert.Equal(t, []int{1, 2}, dequeueIDs(t, queue, 2))
+	require.NoError(t, queue.Ack(ctx, 1))
+	require.NoError(t, queue.Close())
+
+	// simulate crash while writing
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"update":{"update_id":`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	queue, err = OpenUpdateQueueFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, queue.Len(), "not acknowledged updates should be restored")
+
+	update, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, update.ID)
+	assert.Equal(t, 2, update.Message.ID, "update should be restored with content")
+
+	assert.Equal(t, []int{3}, dequeueIDs(t, queue, 1))
+
+	require.NoError(t, queue.Ack(ctx, 2))
+	require.NoError(t, queue.Ack(ctx, 3))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size(), "file should be truncated when queue is empty")
+
+	require.NoError(t, queue.Enqueue(ctx, &tg.Update{ID: 4}))
+	require.NoError(t, queue.Close())
+
+	queue, err = OpenUpdateQueueFile(path)
+	require.NoError(t, err)
+	defer queue.Close()
+
+	assert.Equal(t, []int{4}, dequeueIDs(t, queue, 1))
+
+	t.Run("TornWrite", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "queue.jsonl")
+
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+			`{"update":{"update_id":1}}`,
+			`{"update":{"update_id":`,
+			`{"update":{"update_id":2}}`,
+			`{"ack":1}`,
+			`{"update":{"update_id":3}}`,
+			`{"ack`,
+		}, "\n")), 0o600))
+
+		queue, err := OpenUpdateQueueFile(path)
+		require.NoError(t, err)
+		defer queue.Close()
+
+		assert.Equal(t, []int{2, 3}, dequeueIDs(t, queue, 2), "records after torn line should be restored")
+	})
+}
+
+func TestUpdateQueueFile_Compact(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+
+	queue, err := OpenUpdateQueueFile(path, WithUpdateQueueFileCompactAfter(4))
+	require.NoError(t, err)
+	defer queue.Close()
+
+	lines := func() int {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return strings.Count(string(data), "\n")
+	}
+
+	// update 1 stays in flight, so queue is never empty
+	for id := 1; id <= 4; id++ {
+		require.NoError(t, queue.Enqueue(ctx, &tg.Update{ID: id}))
+	}
+	assert.Equal(t, []int{1, 2}, dequeueIDs(t, queue, 2))
+
+	require.NoError(t, queue.Ack(ctx, 2))
+	assert.Equal(t, 5, lines(), "obsolete records below threshold")
+
+	assert.Equal(t, []int{3}, dequeueIDs(t, queue, 1))
+	require.NoError(t, queue.Ack(ctx, 3))
+	assert.Equal(t, 2, lines(), "file should be compacted to updates in the queue")
+
+	require.NoError(t, queue.Enqueue(ctx, &tg.Update{ID: 5}))
+	assert.Equal(t, 3, lines(), "compacted file should be appended")
+
+	restored, err := OpenUpdateQueueFile(path)
+	require.NoError(t, err)
+	defer restored.Close()
+
+	assert.Equal(t, []int{1, 4, 5}, dequeueIDs(t, restored, 3), "in-flight update should be kept")
+}
+
+func TestUpdateConsumer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue := NewUpdateQueueMemory()
+
+	for _, id := range []int{1, 2, 3} {
+		require.NoError(t, queue.Enqueue(ctx, &tg.Update{ID: id}))
+	}
+
+	var (
+		calls  = make(map[int]int)
+		delays []time.Duration
+		dead   []int
+	)
+
+	errHandler := errors.New("handler error")
+
+	err := NewUpdateConsumer(queue,
+		HandlerFunc(func(ctx context.Context, update *Update) error {
+			calls[update.ID]++
+
+			switch update.ID {
+			case 1:
+				// succeeds on second try
+				if calls[update.ID] == 1 {
+					return errHandler
+				}
+			case 2:
+				return errHandler
+			case 3:
+				cancel()
+			}
+
+			return nil
+		}),
+		&tg.Client{},
+		WithUpdateConsumerTries(3),
+		WithUpdateConsumerBackoff(tg.RetryBackoffExponential(time.Second, 0)),
+		WithUpdateConsumerTimeAfter(func(d time.Duration) <-chan time.Time {
+			delays = append(delays, d)
+			ch := make(chan time.Time, 1)
+			ch <- time.Time{}
+			return ch
+		}),
+		WithUpdateConsumerDeadLetter(func(ctx context.Context, update *tg.Update, err error) {
+			assert.ErrorIs(t, err, errHandler)
+			dead = append(dead, update.ID)
+		}),
+	).Run(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[int]int{1: 2, 2: 3, 3: 1}, calls)
+	assert.Len(t, delays, 3)
+	assert.Equal(t, []int{2}, dead)
+	assert.Zero(t, queue.Len(), "handled and dead updates should be acknowledged")
+
+	t.Run("Interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		queue := NewUpdateQueueMemory()
+		require.NoError(t, queue.Enqueue(ctx, &tg.Update{ID: 1}))
+
+		err := NewUpdateConsumer(queue,
+			HandlerFunc(func(ctx context.Context, update *Update) error {
+				cancel()
+				return ctx.Err()
+			}),
+			&tg.Client{},
+			WithUpdateConsumerDeadLetter(func(ctx context.Context, update *tg.Update, err error) {
+				t.Error("should not be called")
+			}),
+		).Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, queue.Len(), "interrupted update should not be acknowledged")
+		assert.Equal(t, []int{1}, dequeueIDs(t, queue, 1), "interrupted update should be returned to queue")
+	})
+}
@@ -6,8 +6,16 @@ import (
 	"fmt"
 
 	"github.com/mr-linch/go-tg"
+	"golang.org/x/exp/slices"
 )
 
+// AllowedUpdatesSource provides types of updates to receive, e.g. [Router].
+type AllowedUpdatesSource interface {
+	AllowedUpdates() []tg.UpdateType
+}
+
+var _ AllowedUpdatesSource = (*Router)(nil)
+
 // ErrorHandler define interface for error handling in Bot.
 // See Bot.Error for more information.
 type ErrorHandler func(ctx context.Context, update *Update, err error) error
@@ -20,6 +28,11 @@ type Router struct {
 	typedHandlers  map[tg.UpdateType][]Handler
 	updateHandlers []Handler
 
+	// types of updates declared by Update handlers, see [UpdateType]
+	updateTypes []tg.UpdateType
+	// any type of update is handled by Update handler without declared types
+	updateTypesAll bool
+
 	defaultHandler Handler
 	errorHandler   ErrorHandler
 }
@@ -97,9 +110,21 @@ func (bot *Router) Error(handler ErrorHandler) *Router {
 // Update registers a generic Update handler.
 // It will be called as typed handlers only in filters match the update.
 // First check Update handler, then typed.
+// Use [UpdateType] filter to declare types of handled updates, see [Router.AllowedUpdates].
 func (bot *Router) Update(handler HandlerFunc, filters ...Filter) *Router {
 	fitler := compactFilters(filters...)
 
+	declared := false
+	for _, filter := range filters {
+		if filter, ok := filter.(*updateTypeFilter); ok {
+			bot.updateTypes = append(bot.updateTypes, filter.types...)
+			declared = true
+		}
+	}
+	if !declared {
+		bot.updateTypesAll = true
+	}
+
 	bot.updateHandlers = append(bot.updateHandlers,
 		bot.chain.Append(filterMiddleware(fitler)).Then(handler),
 	)
@@ -107,6 +132,43 @@ func (bot *Router) Update(handler HandlerFunc, filters ...Filter) *Router {
 	return bot
 }
 
+// AllowedUpdates returns types of updates handled by router, sorted,
+// so it can be used as allowed_updates of getUpdates or setWebhook.
+// See [WithPollerAllowedUpdatesFrom] and [WithWebhookAllowedUpdatesFrom].
+//
+// Types of typed handlers and types declared by [UpdateType] filter of Update handlers are included.
+// If any Update handler has no [UpdateType] filter, all types are returned, since it handles any update.
+// If router has no handlers, only the default one, nil is returned, so allowed updates are not changed.
+func (bot *Router) AllowedUpdates() []tg.UpdateType {
+	if bot.updateTypesAll {
+		return allUpdateTypes()
+	}
+
+	if len(bot.updateTypes) == 0 && len(bot.typedHandlers) == 0 {
+		return nil
+	}
+
+	types := append([]tg.UpdateType{}, bot.updateTypes...)
+	for typ := range bot.typedHandlers {
+		types = append(types, typ)
+	}
+
+	slices.Sort(types)
+
+	return slices.Compact(types)
+}
+
+// allUpdateTypes returns all known update types.
+func allUpdateTypes() []tg.UpdateType {
+	types := make([]tg.UpdateType, 0, tg.UpdateTypeRemovedChatBoost)
+
+	for typ := tg.UpdateTypeMessage; typ <= tg.UpdateTypeRemovedChatBoost; typ++ {
+		types = append(types, typ)
+	}
+
+	return types
+}
+
 func (bot *Router) getDefaultHandler() Handler {
 	return bot.chain.Then(bot.defaultHandler)
 }
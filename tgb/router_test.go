@@ -565,3 +565,45 @@ func TestRouter(t *testing.T) {
 		assert.False(t, isGroupAndPrivateChatHandlerCalled, "group and private chat handler should not be called")
 	})
 }
+
+func TestRouter_AllowedUpdates(t *testing.T) {
+	noop := func(ctx context.Context, update *Update) error { return nil }
+
+	t.Run("Typed", func(t *testing.T) {
+		router := NewRouter().
+			ChatMember(func(ctx context.Context, cmu *ChatMemberUpdatedUpdate) error { return nil }).
+			Message(func(ctx context.Context, mu *MessageUpdate) error { return nil }).
+			Message(func(ctx context.Context, mu *MessageUpdate) error { return nil }, Command("start")).
+			Update(noop, UpdateType(tg.UpdateTypeMyChatMember, tg.UpdateTypeMessage))
+
+		assert.Equal(t, []tg.UpdateType{
+			tg.UpdateTypeMessage,
+			tg.UpdateTypeMyChatMember,
+			tg.UpdateTypeChatMember,
+		}, router.AllowedUpdates())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Nil(t, NewRouter().AllowedUpdates())
+	})
+
+	t.Run("DefaultOnly", func(t *testing.T) {
+		router := NewRouter().
+			Use(MiddlewareFunc(func(next Handler) Handler { return next })).
+			Error(func(ctx context.Context, update *Update, err error) error { return err })
+
+		assert.Nil(t, router.AllowedUpdates())
+	})
+
+	t.Run("UpdateWithoutTypes", func(t *testing.T) {
+		router := NewRouter().
+			Message(func(ctx context.Context, mu *MessageUpdate) error { return nil }).
+			Update(noop)
+
+		types := router.AllowedUpdates()
+		assert.Contains(t, types, tg.UpdateTypeChatMember)
+		assert.Contains(t, types, tg.UpdateTypeRemovedChatBoost)
+		assert.NotContains(t, types, tg.UpdateTypeUnknown)
+		assert.Len(t, types, int(tg.UpdateTypeRemovedChatBoost))
+	})
+}
@@ -25,6 +25,7 @@ import (
 	"strconv"
 	"sync"
 
+	"github.com/mr-linch/go-tg"
 	"github.com/mr-linch/go-tg/tgb"
 )
 
@@ -36,12 +37,17 @@ func KeyFuncChat(update *tgb.Update) string {
 	chat := update.Chat()
 
 	if chat != nil {
-		return strconv.Itoa(int(chat.ID))
+		return KeyChat(chat.ID)
 	}
 
 	return ""
 }
 
+// KeyChat returns a key of chat session, same as [KeyFuncChat] generates.
+func KeyChat(id tg.ChatID) string {
+	return strconv.Itoa(int(id))
+}
+
 type managerSettings interface {
 	setKeyFunc(KeyFunc)
 	setStore(Store)
@@ -201,6 +207,36 @@ func (manager *Manager[T]) Reset(session *T) {
 	*session = manager.initial
 }
 
+// MigrateChat moves session data of the chat to the new chat id.
+// It's designed for sessions keyed by [KeyFuncChat] and group to supergroup migration.
+// Can be used as hook of [github.com/mr-linch/go-tg.NewInterceptorChatMigration]:
+//
+//	tg.NewInterceptorChatMigration(
+//	  tg.WithInterceptorChatMigrationHook(manager.MigrateChat),
+//	)
+func (manager *Manager[T]) MigrateChat(ctx context.Context, from, to tg.ChatID) error {
+	fromKey, toKey := KeyChat(from), KeyChat(to)
+
+	data, err := manager.store.Get(ctx, fromKey)
+	if err != nil {
+		return fmt.Errorf("get session from store: %w", err)
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	if err := manager.store.Set(ctx, toKey, data); err != nil {
+		return fmt.Errorf("save session to store: %w", err)
+	}
+
+	if err := manager.store.Del(ctx, fromKey); err != nil {
+		return fmt.Errorf("delete old session from store: %w", err)
+	}
+
+	return nil
+}
+
 // Filter creates a [github.com/mr-linch/go-tg/tgb.Filter] based on Session data.
 //
 // Example:
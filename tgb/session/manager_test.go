@@ -604,6 +604,50 @@ func TestKeyFuncChat(t *testing.T) {
 	assert.Empty(t, key)
 }
 
+func TestManager_MigrateChat(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Exists", func(t *testing.T) {
+		store := NewStoreMemory()
+		require.NoError(t, store.Set(ctx, "-123", []byte(`{"Count":1}`)))
+
+		manager := NewManager(struct{ Count int }{}, WithStore(store))
+
+		require.NoError(t, manager.MigrateChat(ctx, -123, -100123))
+
+		data, err := store.Get(ctx, "-100123")
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`{"Count":1}`), data)
+
+		data, err = store.Get(ctx, "-123")
+		require.NoError(t, err)
+		assert.Nil(t, data)
+	})
+
+	t.Run("NotExists", func(t *testing.T) {
+		store := NewStoreMemory()
+
+		manager := NewManager(struct{ Count int }{}, WithStore(store))
+
+		require.NoError(t, manager.MigrateChat(ctx, -123, -100123))
+
+		data, err := store.Get(ctx, "-100123")
+		require.NoError(t, err)
+		assert.Nil(t, data)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		store := &StoreMock{}
+		store.On("Get", mock.Anything, "-123").Return(nil, errors.New("test"))
+
+		manager := NewManager(struct{ Count int }{}, WithStore(store))
+
+		require.Error(t, manager.MigrateChat(ctx, -123, -100123))
+
+		store.AssertExpectations(t)
+	})
+}
+
 func TestManager_Filter(t *testing.T) {
 	t.Run("NoSession", func(t *testing.T) {
 		type Session struct{}
@@ -0,0 +1,106 @@
+package tgb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	tg "github.com/mr-linch/go-tg"
+)
+
+type slogMiddlewareOpts struct {
+	level      slog.Level
+	errorLevel slog.Level
+}
+
+// SlogMiddlewareOption is an option for NewSlogMiddleware.
+type SlogMiddlewareOption func(*slogMiddlewareOpts)
+
+// WithSlogMiddlewareLevel sets the level of successfully handled updates log records.
+// Default is slog.LevelDebug.
+func WithSlogMiddlewareLevel(level slog.Level) SlogMiddlewareOption {
+	return func(o *slogMiddlewareOpts) {
+		o.level = level
+	}
+}
+
+// WithSlogMiddlewareErrorLevel sets the level of failed updates log records.
+// Default is slog.LevelError.
+func WithSlogMiddlewareErrorLevel(level slog.Level) SlogMiddlewareOption {
+	return func(o *slogMiddlewareOpts) {
+		o.errorLevel = level
+	}
+}
+
+// NewSlogMiddleware returns a new middleware that logs handled updates with [log/slog].
+// It's a counterpart of [tg.NewInterceptorSlog] for incoming updates.
+//
+// Each record contains update_id, update_type, chat_id and user_id (if present) and duration of handler.
+// Failed updates have error and error_kind (see [tg.ErrorKind]) attributes.
+// Bot token is removed from error message.
+func NewSlogMiddleware(logger *slog.Logger, opts ...SlogMiddlewareOption) Middleware {
+	options := &slogMiddlewareOpts{
+		level:      slog.LevelDebug,
+		errorLevel: slog.LevelError,
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	return MiddlewareFunc(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *Update) error {
+			start := time.Now()
+
+			err := next.Handle(ctx, update)
+
+			level := options.level
+			if err != nil {
+				level = options.errorLevel
+			}
+
+			if !logger.Enabled(ctx, level) {
+				return err
+			}
+
+			attrs := []slog.Attr{
+				slog.Int("update_id", update.ID),
+				slog.String("update_type", update.Type().String()),
+			}
+
+			if chatID := update.ChatID(); chatID != 0 {
+				attrs = append(attrs, slog.Int64("chat_id", int64(chatID)))
+			}
+
+			if user := update.User(); user != nil {
+				attrs = append(attrs, slog.Int64("user_id", int64(user.ID)))
+			}
+
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+			if err != nil {
+				kind := tg.ErrorKindUnknown
+
+				var tgErr *tg.Error
+				if errors.As(err, &tgErr) {
+					kind = tgErr.Kind()
+				}
+
+				logErr := err
+				if update.Client != nil {
+					logErr = update.Client.RedactError(err)
+				}
+
+				attrs = append(attrs,
+					slog.String("error", logErr.Error()),
+					slog.String("error_kind", kind.String()),
+				)
+			}
+
+			logger.LogAttrs(ctx, level, "tgb update", attrs...)
+
+			return err
+		})
+	})
+}
@@ -0,0 +1,81 @@
+package tgb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	tg "github.com/mr-linch/go-tg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSlogMiddleware(t *testing.T) {
+	newLogger := func(buf *bytes.Buffer) *slog.Logger {
+		return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey || a.Key == "duration" {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+	}
+
+	update := &Update{
+		Update: &tg.Update{
+			ID: 1,
+			Message: &tg.Message{
+				Chat: tg.Chat{ID: 2},
+				From: &tg.User{ID: 3},
+			},
+		},
+		Client: tg.New("1234:secret"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		handler := NewSlogMiddleware(newLogger(buf)).Wrap(HandlerFunc(func(ctx context.Context, update *Update) error {
+			return nil
+		}))
+
+		require.NoError(t, handler.Handle(context.Background(), update))
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+		assert.Equal(t, map[string]any{
+			"level":       "DEBUG",
+			"msg":         "tgb update",
+			"update_id":   1.0,
+			"update_type": "message",
+			"chat_id":     2.0,
+			"user_id":     3.0,
+		}, record)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		handlerErr := &tg.Error{Code: 403, Message: "Forbidden: bot was blocked by the user 1234:secret"}
+
+		handler := NewSlogMiddleware(newLogger(buf), WithSlogMiddlewareErrorLevel(slog.LevelWarn)).Wrap(HandlerFunc(func(ctx context.Context, update *Update) error {
+			return handlerErr
+		}))
+
+		err := handler.Handle(context.Background(), update)
+		assert.True(t, errors.Is(err, handlerErr), "should return original error")
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, "403: Forbidden: bot was blocked by the user <redacted>", record["error"])
+		assert.Equal(t, "bot_blocked", record["error_kind"])
+	})
+}
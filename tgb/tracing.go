@@ -0,0 +1,34 @@
+package tgb
+
+import (
+	"context"
+
+	tg "github.com/mr-linch/go-tg"
+)
+
+// handleTraced handles the update in span of tracer (if any).
+// Span has tgb.update_id, tgb.update_type, tgb.chat_id and tgb.user_id (if present) attributes.
+func handleTraced(ctx context.Context, tracer tg.Tracer, name string, handler Handler, update *Update) error {
+	if tracer == nil {
+		return handler.Handle(ctx, update)
+	}
+
+	ctx, span := tracer.Start(ctx, name)
+
+	span.SetAttribute("tgb.update_id", update.ID)
+	span.SetAttribute("tgb.update_type", update.Type().String())
+
+	if chatID := update.ChatID(); chatID != 0 {
+		span.SetAttribute("tgb.chat_id", int64(chatID))
+	}
+
+	if user := update.User(); user != nil {
+		span.SetAttribute("tgb.user_id", int64(user.ID))
+	}
+
+	err := handler.Handle(ctx, update)
+
+	span.End(err)
+
+	return err
+}
@@ -0,0 +1,136 @@
+package tgb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+
+	tg "github.com/mr-linch/go-tg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tracerSpanKey struct{}
+
+type tracerMock struct {
+	lock  sync.Mutex
+	spans []*spanMock
+}
+
+type spanMock struct {
+	name   string
+	parent *spanMock
+	attrs  map[string]any
+	err    error
+}
+
+func (tracer *tracerMock) Start(ctx context.Context, name string) (context.Context, tg.Span) {
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+
+	parent, _ := ctx.Value(tracerSpanKey{}).(*spanMock)
+
+	span := &spanMock{name: name, parent: parent, attrs: map[string]any{}}
+	tracer.spans = append(tracer.spans, span)
+
+	return context.WithValue(ctx, tracerSpanKey{}, span), span
+}
+
+func (span *spanMock) SetAttribute(key string, value any) {
+	span.attrs[key] = value
+}
+
+func (span *spanMock) End(err error) {
+	span.err = err
+}
+
+type doerFunc func(r *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWebhook_Tracer(t *testing.T) {
+	for _, reply := range []bool{true, false} {
+		tracer := &tracerMock{}
+
+		client := tg.New("1234:secret",
+			tg.WithClientDoer(doerFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"ok":true,"result":true}`)),
+				}, nil
+			})),
+			tg.WithClientInterceptors(tg.NewInterceptorTracing(tracer)),
+		)
+
+		webhook := NewWebhook(
+			HandlerFunc(func(ctx context.Context, update *Update) error {
+				return update.Client.Do(ctx, tg.NewRequest("getMe"), nil)
+			}),
+			client,
+			"http://test.io/",
+			WithWebhookSecuritySubnets(),
+			WithWebhookSecurityToken(""),
+			WithWebhookReply(reply),
+			WithWebhookTracer(tracer),
+		)
+
+		ctx, parent := tracer.Start(context.Background(), "http")
+
+		response := webhook.ServeRequest(ctx, &WebhookRequest{
+			Method:      http.MethodPost,
+			ContentType: "application/json",
+			IP:          netip.MustParseAddr("1.1.1.1"),
+			Body:        strings.NewReader(`{"update_id": 1, "message": {"chat": {"id": 2}, "from": {"id": 3}}}`),
+		})
+		require.Equal(t, http.StatusOK, response.Status)
+
+		require.Len(t, tracer.spans, 3)
+
+		update, request := tracer.spans[1], tracer.spans[2]
+
+		assert.Equal(t, "tgb.Webhook.update", update.name)
+		assert.Same(t, parent, update.parent, "update span should be child of request span")
+		assert.Equal(t, map[string]any{
+			"tgb.update_id":   1,
+			"tgb.update_type": "message",
+			"tgb.chat_id":     int64(2),
+			"tgb.user_id":     int64(3),
+		}, update.attrs)
+
+		assert.Equal(t, "tg.getMe", request.name)
+		assert.Same(t, update, request.parent, "client request span should be child of update span")
+	}
+}
+
+func TestPoller_Tracer(t *testing.T) {
+	tracer := &tracerMock{}
+
+	poller := NewPoller(
+		HandlerFunc(func(ctx context.Context, update *Update) error {
+			span, _ := ctx.Value(tracerSpanKey{}).(*spanMock)
+			require.NotNil(t, span, "handler context should contain span")
+			assert.Equal(t, "tgb.Poller.update", span.name)
+			return nil
+		}),
+		&tg.Client{},
+		WithPollerTracer(tracer),
+	)
+
+	poller.processUpdates(context.Background(), []tg.Update{
+		{ID: 1, CallbackQuery: &tg.CallbackQuery{From: tg.User{ID: 3}}},
+	})
+	poller.wg.Wait()
+
+	require.Len(t, tracer.spans, 1)
+	assert.Equal(t, map[string]any{
+		"tgb.update_id":   1,
+		"tgb.update_type": "callback_query",
+		"tgb.user_id":     int64(3),
+	}, tracer.spans[0].attrs)
+}
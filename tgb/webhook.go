@@ -29,6 +29,7 @@ type Webhook struct {
 	maxConnections     int
 	dropPendingUpdates bool
 	allowedUpdates     []tg.UpdateType
+	allowedFrom        AllowedUpdatesSource
 
 	securitySubnets []netip.Prefix
 	securityToken   string
@@ -37,6 +38,10 @@ type Webhook struct {
 
 	webhookReplyEnabled bool
 
+	tracer     tg.Tracer
+	dispatcher *Dispatcher
+	queue      UpdateQueue
+
 	isSetup bool
 }
 
@@ -118,6 +123,15 @@ func WithWebhookAllowedUpdates(updates ...tg.UpdateType) WebhookOption {
 	}
 }
 
+// WithWebhookAllowedUpdatesFrom sets the allowed updates from source, e.g. [Router.AllowedUpdates].
+// Source is called on [Webhook.Setup], so handlers registered after NewWebhook are taken into account.
+// Overrides [WithWebhookAllowedUpdates].
+func WithWebhookAllowedUpdatesFrom(source AllowedUpdatesSource) WebhookOption {
+	return func(webhook *Webhook) {
+		webhook.allowedFrom = source
+	}
+}
+
 // WithWebhookReply controls whether the first Update.Reply call returns its response
 // directly in the webhook HTTP response body (bypassing the Client and interceptor chain).
 // Enabled by default. When disabled, all Reply calls go through Client.Do as usual.
@@ -127,6 +141,34 @@ func WithWebhookReply(enabled bool) WebhookOption {
 	}
 }
 
+// WithWebhookTracer sets the tracer for the webhook.
+// Each update is handled in span named "tgb.Webhook.update", started from context of request,
+// so requests made by handler with [tg.NewInterceptorTracing] are its children.
+func WithWebhookTracer(tracer tg.Tracer) WebhookOption {
+	return func(webhook *Webhook) {
+		webhook.tracer = tracer
+	}
+}
+
+// WithWebhookDispatcher sets the dispatcher of updates.
+// Webhook request waits until the update is dispatched and handled (or replied), as without dispatcher.
+// If the request is canceled while waiting for free worker, 503 is returned, so Telegram redelivers the update.
+func WithWebhookDispatcher(dispatcher *Dispatcher) WebhookOption {
+	return func(webhook *Webhook) {
+		webhook.dispatcher = dispatcher
+	}
+}
+
+// WithWebhookQueue makes webhook enqueue updates instead of handling them,
+// use [UpdateConsumer] to handle updates from the queue.
+// Telegram request is responded as soon as the update is enqueued, so replying via webhook is not available.
+// If the update is not enqueued, 503 is returned, so Telegram redelivers it.
+func WithWebhookQueue(queue UpdateQueue) WebhookOption {
+	return func(webhook *Webhook) {
+		webhook.queue = queue
+	}
+}
+
 func NewWebhook(handler Handler, client *tg.Client, url string, options ...WebhookOption) *Webhook {
 	securityToken := sha256.Sum256([]byte(client.Token()))
 	token := hex.EncodeToString(securityToken[:])
@@ -157,7 +199,7 @@ func NewWebhook(handler Handler, client *tg.Client, url string, options ...Webho
 
 func (webhook *Webhook) log(format string, args ...any) {
 	if webhook.logger != nil {
-		webhook.logger.Printf("tgb.Webhook: "+format, args...)
+		webhook.logger.Printf("tgb.Webhook: "+format, redactLogArgs(webhook.client, args)...)
 	}
 }
 
@@ -168,6 +210,10 @@ func (webhook *Webhook) Setup(ctx context.Context) (err error) {
 		webhook.isSetup = err == nil
 	}()
 
+	if webhook.allowedFrom != nil {
+		webhook.allowedUpdates = webhook.allowedFrom.AllowedUpdates()
+	}
+
 	info, err := webhook.client.GetWebhookInfo().Do(ctx)
 	if err != nil {
 		return fmt.Errorf("get webhook info: %w", err)
@@ -211,7 +257,8 @@ func (webhook *Webhook) needsUpdate(info tg.WebhookInfo) bool {
 	if info.MaxConnections != webhook.maxConnections {
 		return true
 	}
-	if len(info.AllowedUpdates) > 0 && !slices.Equal(info.AllowedUpdates, webhook.allowedUpdates) {
+	// nil keeps allowed updates set before
+	if webhook.allowedUpdates != nil && len(info.AllowedUpdates) > 0 && !slices.Equal(info.AllowedUpdates, webhook.allowedUpdates) {
 		return true
 	}
 	if webhook.ip != "" && info.IPAddress != webhook.ip {
@@ -314,14 +361,54 @@ func (webhook *Webhook) ServeRequest(ctx context.Context, r *WebhookRequest) *We
 		}
 	}
 
+	if webhook.queue != nil {
+		if err := webhook.queue.Enqueue(ctx, baseUpdate); err != nil {
+			webhook.log("update %d is not enqueued: %v", baseUpdate.ID, err)
+
+			return &WebhookResponse{
+				Status:      http.StatusServiceUnavailable,
+				ContentType: "text/plain",
+				Body:        []byte("update is not enqueued"),
+			}
+		}
+
+		return &WebhookResponse{
+			Status: http.StatusOK,
+		}
+	}
+
 	if !webhook.webhookReplyEnabled {
 		update := &Update{
 			Update: baseUpdate,
 			Client: webhook.client,
 		}
 
-		if err := webhook.handler.Handle(ctx, update); err != nil {
-			webhook.log("handler error: %v", err)
+		if webhook.dispatcher == nil {
+			if err := handleTraced(ctx, webhook.tracer, "tgb.Webhook.update", webhook.handler, update); err != nil {
+				webhook.log("handler error: %v", err)
+			}
+
+			return &WebhookResponse{
+				Status: http.StatusOK,
+			}
+		}
+
+		done := make(chan struct{})
+
+		err := webhook.dispatcher.Dispatch(ctx, update, func() {
+			defer close(done)
+
+			if err := handleTraced(ctx, webhook.tracer, "tgb.Webhook.update", webhook.handler, update); err != nil {
+				webhook.log("handler error: %v", err)
+			}
+		})
+		if err != nil {
+			return webhook.notDispatched(update, err)
+		}
+
+		select {
+		case <-done:
+		case <-ctx.Done():
 		}
 
 		return &WebhookResponse{
@@ -334,18 +421,25 @@ func (webhook *Webhook) ServeRequest(ctx context.Context, r *WebhookRequest) *We
 
 	done := make(chan struct{})
 
-	//nolint:contextcheck // handler runs independently from HTTP request lifecycle
-	go func() {
-		handlerCtx, handlerCtxClose := context.WithCancel(context.Background())
+	handle := func() {
+		// handler runs independently from HTTP request lifecycle,
+		// but keeps values of request context (e.g. tracing span)
+		handlerCtx, handlerCtxClose := context.WithCancel(context.WithoutCancel(ctx))
 		defer handlerCtxClose()
 
 		// handle update
-		if err := webhook.handler.Handle(handlerCtx, update); err != nil {
+		if err := handleTraced(handlerCtx, webhook.tracer, "tgb.Webhook.update", webhook.handler, update); err != nil {
 			webhook.log("handler error: %v", err)
 		}
 
 		close(done)
-	}()
+	}
+
+	if webhook.dispatcher == nil {
+		go handle()
+	} else if err := webhook.dispatcher.Dispatch(ctx, update, handle); err != nil {
+		return webhook.notDispatched(update, err)
+	}
 
 	select {
 	case <-ctx.Done():
@@ -376,6 +470,18 @@ func (webhook *Webhook) ServeRequest(ctx context.Context, r *WebhookRequest) *We
 	}
 }
 
+// notDispatched returns the response for update which is not dispatched,
+// so Telegram delivers it again.
+func (webhook *Webhook) notDispatched(update *Update, err error) *WebhookResponse {
+	webhook.log("update %d is not dispatched: %v", update.ID, err)
+
+	return &WebhookResponse{
+		Status:      http.StatusServiceUnavailable,
+		ContentType: "text/plain",
+		Body:        []byte("update is not dispatched"),
+	}
+}
+
 // ServeHTTP is the HTTP handler for webhook requests.
 // Implementation of http.Handler.
 func (webhook *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
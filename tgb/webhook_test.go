@@ -9,6 +9,7 @@ import (
 	"net/netip"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -465,6 +466,67 @@ func TestWebhook_Setup(t *testing.T) {
 		err := webhook.Setup(context.Background())
 		require.NoError(t, err)
 	})
+
+	t.Run("AllowedUpdatesFromRouter", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/bot1234:secret/getWebhookInfo":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"ok":true,"result":{"url":"https://google.com","max_connections":40,"allowed_updates":["message"]}}`))
+			case "/bot1234:secret/setWebhook":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"ok":true,"result": true}`))
+
+				assert.Equal(t, `["message","callback_query"]`, r.FormValue("allowed_updates"))
+			default:
+				t.Fatalf("unexcepted call '%s'", r.URL.Path)
+			}
+		}))
+
+		defer server.Close()
+
+		router := NewRouter()
+
+		webhook := NewWebhook(
+			router,
+			tg.New("1234:secret", tg.WithClientServerURL(server.URL), tg.WithClientDoer(server.Client())),
+			"https://google.com",
+			WithWebhookAllowedUpdatesFrom(router),
+		)
+
+		router.
+			CallbackQuery(func(ctx context.Context, cbq *CallbackQueryUpdate) error { return nil }).
+			Message(func(ctx context.Context, mu *MessageUpdate) error { return nil })
+
+		err := webhook.Setup(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("AllowedUpdatesFromRouterWithoutHandlers", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/bot1234:secret/getWebhookInfo":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"ok":true,"result":{"url":"https://google.com","max_connections":40,"allowed_updates":["message"]}}`))
+			default:
+				t.Fatalf("unexcepted call '%s'", r.URL.Path)
+			}
+		}))
+
+		defer server.Close()
+
+		router := NewRouter()
+
+		webhook := NewWebhook(
+			router,
+			tg.New("1234:secret", tg.WithClientServerURL(server.URL), tg.WithClientDoer(server.Client())),
+			"https://google.com",
+			WithWebhookAllowedUpdatesFrom(router),
+		)
+
+		err := webhook.Setup(context.Background())
+		require.NoError(t, err)
+	})
 }
 
 type loggerMock struct {
@@ -522,3 +584,90 @@ func TestWebhook_Run(t *testing.T) {
 
 	cancel()
 }
+
+func TestWebhook_Dispatcher(t *testing.T) {
+	newRequest := func() *WebhookRequest {
+		return &WebhookRequest{
+			Method:      http.MethodPost,
+			ContentType: "application/json",
+			IP:          netip.MustParseAddr("1.1.1.1"),
+			Body:        strings.NewReader(`{"update_id": 1, "message": {"chat": {"id": 2}}}`),
+		}
+	}
+
+	for _, reply := range []bool{true, false} {
+		var handled atomic.Bool
+
+		webhook := NewWebhook(
+			HandlerFunc(func(ctx context.Context, update *Update) error {
+				handled.Store(true)
+				return nil
+			}),
+			&tg.Client{},
+			"http://test.io/",
+			WithWebhookSecuritySubnets(),
+			WithWebhookSecurityToken(""),
+			WithWebhookReply(reply),
+			WithWebhookDispatcher(NewDispatcher()),
+		)
+
+		response := webhook.ServeRequest(context.Background(), newRequest())
+		assert.Equal(t, http.StatusOK, response.Status)
+		assert.True(t, handled.Load(), "handler should be called (reply: %v)", reply)
+	}
+
+	t.Run("NotDispatched", func(t *testing.T) {
+		dispatcher := NewDispatcher(WithDispatcherWorkers(1))
+
+		release := make(chan struct{})
+		defer close(release)
+
+		err := dispatcher.Dispatch(context.Background(), newDispatcherTestUpdate(1, 1), func() {
+			<-release
+		})
+		require.NoError(t, err)
+
+		webhook := NewWebhook(
+			HandlerFunc(func(ctx context.Context, update *Update) error {
+				t.Error("should not be called")
+				return nil
+			}),
+			&tg.Client{},
+			"http://test.io/",
+			WithWebhookSecuritySubnets(),
+			WithWebhookSecurityToken(""),
+			WithWebhookDispatcher(dispatcher),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		response := webhook.ServeRequest(ctx, newRequest())
+		assert.Equal(t, http.StatusServiceUnavailable, response.Status)
+	})
+}
+
+func TestWebhook_Queue(t *testing.T) {
+	queue := NewUpdateQueueMemory()
+
+	webhook := NewWebhook(
+		HandlerFunc(func(ctx context.Context, update *Update) error {
+			t.Error("should not be called")
+			return nil
+		}),
+		&tg.Client{},
+		"http://test.io/",
+		WithWebhookSecuritySubnets(),
+		WithWebhookSecurityToken(""),
+		WithWebhookQueue(queue),
+	)
+
+	response := webhook.ServeRequest(context.Background(), &WebhookRequest{
+		Method:      http.MethodPost,
+		ContentType: "application/json",
+		IP:          netip.MustParseAddr("1.1.1.1"),
+		Body:        strings.NewReader(`{"update_id": 1, "message": {"chat": {"id": 2}}}`),
+	})
+	assert.Equal(t, http.StatusOK, response.Status)
+	assert.Equal(t, 1, queue.Len())
+}
@@ -0,0 +1,59 @@
+package tg
+
+import (
+	"context"
+)
+
+// Tracer starts spans of tracing system, e.g. OpenTelemetry.
+// Implement it with adapter to your tracing library, go-tg doesn't depend on any.
+//
+// Example of OpenTelemetry adapter:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, tg.Span) {
+//	  ctx, span := t.Tracer.Start(ctx, name)
+//	  return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	// Start starts a new span as child of span from ctx (if any).
+	// Returned context should contain the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation, started by [Tracer].
+type Span interface {
+	// SetAttribute sets attribute of span. Value is string, int, int64 or bool.
+	SetAttribute(key string, value any)
+
+	// End finishes the span. Err is not nil if operation failed.
+	End(err error)
+}
+
+// NewInterceptorTracing returns a new interceptor that wraps each request in span of tracer.
+//
+// Span is named "tg.<method>", e.g. "tg.sendMessage", and has tg.method and tg.chat_id (if present) attributes.
+// Failed requests have tg.error_kind attribute (see [ErrorKind]).
+// Span is started from request context, so requests made by update handlers are children of the update span
+// (see tgb.WithPollerTracer and tgb.WithWebhookTracer).
+// Register it before retrying interceptors to trace the whole call, or after them to trace each try.
+func NewInterceptorTracing(tracer Tracer) Interceptor {
+	return func(ctx context.Context, req *Request, dst any, invoker InterceptorInvoker) error {
+		ctx, span := tracer.Start(ctx, "tg."+req.Method)
+
+		span.SetAttribute("tg.method", req.Method)
+
+		if chatID, ok := req.GetArg("chat_id"); ok {
+			span.SetAttribute("tg.chat_id", chatID)
+		}
+
+		err := invoker(ctx, req, dst)
+		if err != nil {
+			span.SetAttribute("tg.error_kind", errorKindOf(err).String())
+		}
+
+		span.End(err)
+
+		return err
+	}
+}
@@ -0,0 +1,89 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tracerSpanKey struct{}
+
+type tracerMock struct {
+	spans []*spanMock
+}
+
+type spanMock struct {
+	name   string
+	parent *spanMock
+	attrs  map[string]any
+	ended  bool
+	err    error
+}
+
+func (tracer *tracerMock) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(tracerSpanKey{}).(*spanMock)
+
+	span := &spanMock{name: name, parent: parent, attrs: map[string]any{}}
+	tracer.spans = append(tracer.spans, span)
+
+	return context.WithValue(ctx, tracerSpanKey{}, span), span
+}
+
+func (span *spanMock) SetAttribute(key string, value any) {
+	span.attrs[key] = value
+}
+
+func (span *spanMock) End(err error) {
+	span.ended = true
+	span.err = err
+}
+
+func TestNewInterceptorTracing(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		tracer := &tracerMock{}
+
+		ctx, parent := tracer.Start(context.Background(), "parent")
+
+		interceptor := NewInterceptorTracing(tracer)
+
+		err := interceptor(ctx, NewRequest("sendMessage").String("chat_id", "1"), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				span, _ := ctx.Value(tracerSpanKey{}).(*spanMock)
+				require.NotNil(t, span, "should pass span in context")
+				assert.Equal(t, "tg.sendMessage", span.name)
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		require.Len(t, tracer.spans, 2)
+
+		span := tracer.spans[1]
+		assert.Same(t, parent, span.parent)
+		assert.Equal(t, map[string]any{"tg.method": "sendMessage", "tg.chat_id": "1"}, span.attrs)
+		assert.True(t, span.ended)
+		assert.NoError(t, span.err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		tracer := &tracerMock{}
+
+		interceptor := NewInterceptorTracing(tracer)
+
+		requestErr := &Error{Code: 400, Message: "Bad Request: chat not found"}
+
+		err := interceptor(context.Background(), NewRequest("getChat"), nil,
+			func(ctx context.Context, req *Request, dst any) error {
+				return requestErr
+			},
+		)
+		require.True(t, errors.Is(err, requestErr))
+
+		require.Len(t, tracer.spans, 1)
+		assert.Equal(t, "chat_not_found", tracer.spans[0].attrs["tg.error_kind"])
+		assert.Equal(t, requestErr, tracer.spans[0].err)
+	})
+}
@@ -196,6 +196,53 @@ func (u *InputMedia) getMedia() (media *FileArg, thumb *InputFile) {
 	}
 }
 
+// kind returns the type of media in an InputMedia union, e.g. "photo".
+func (u *InputMedia) kind() string {
+	switch {
+	case u.Photo != nil:
+		return "photo"
+	case u.Video != nil:
+		return "video"
+	case u.Animation != nil:
+		return "animation"
+	case u.Audio != nil:
+		return "audio"
+	case u.Document != nil:
+		return "document"
+	default:
+		return ""
+	}
+}
+
+// withFileID returns a copy of InputMedia with media replaced by file identifier.
+// Original media struct is not modified.
+func (u InputMedia) withFileID(id FileID) InputMedia {
+	switch {
+	case u.Photo != nil:
+		v := *u.Photo
+		v.Media = NewFileArgID(id)
+		u.Photo = &v
+	case u.Video != nil:
+		v := *u.Video
+		v.Media = NewFileArgID(id)
+		u.Video = &v
+	case u.Animation != nil:
+		v := *u.Animation
+		v.Media = NewFileArgID(id)
+		u.Animation = &v
+	case u.Audio != nil:
+		v := *u.Audio
+		v.Media = NewFileArgID(id)
+		u.Audio = &v
+	case u.Document != nil:
+		v := *u.Document
+		v.Media = NewFileArgID(id)
+		u.Document = &v
+	}
+
+	return u
+}
+
 // getMedia returns the media, thumbnail and cover from an InputPaidMedia union.
 func (u *InputPaidMedia) getMedia() (media *FileArg, thumb *InputFile, cover *FileArg) {
 	switch {